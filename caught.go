@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"pokedexcli/internal/api"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shinyOdds is the one-in chance of a caught Pokemon being shiny
+const shinyOdds = 4096
+
+// defaultBall is thrown unless catch --ball picks another
+const defaultBall = "poke-ball"
+
+// balls holds how much each Pokeball multiplies the catch rate. The Master Ball never fails.
+var balls = map[string]float64{
+	"poke-ball":   1,
+	"great-ball":  1.5,
+	"ultra-ball":  2,
+	"master-ball": math.Inf(1),
+}
+
+// caughtPokemon is one Pokemon the trainer caught. Several can be of the same
+// species, each with its own ID, nickname and the details of its catch.
+type caughtPokemon struct {
+	ID       int       `json:"id"`
+	Species  string    `json:"species"`
+	Nickname string    `json:"nickname,omitempty"`
+	Level    int       `json:"level,omitempty"`
+	CaughtAt time.Time `json:"caught_at"`
+	Location string    `json:"location,omitempty"`
+	Ball     string    `json:"ball"`
+	Shiny    bool      `json:"shiny"`
+}
+
+// dexPokemon is what the Pokedex keeps about a caught species or form: its species
+// and what inspect shows. Everything else is fetched again when it is needed.
+type dexPokemon struct {
+	Name    string            `json:"name"`
+	Species api.NamedResource `json:"species"`
+	Height  int               `json:"height"`
+	Weight  int               `json:"weight"`
+	Stats   []statValue       `json:"stats"`
+	Types   []string          `json:"types"`
+}
+
+func newDexPokemon(mon api.Pokemon) dexPokemon {
+	return dexPokemon{
+		Name:    mon.Name,
+		Species: api.NamedResource{Name: mon.Species.Name, URL: mon.Species.URL},
+		Height:  mon.Height,
+		Weight:  mon.Weight,
+		Stats:   pokemonStats(mon),
+		Types:   pokemonTypes(mon),
+	}
+}
+
+// label is how a caught Pokemon is referred to: its ID and nickname
+func (p caughtPokemon) label() string {
+	if p.Nickname == "" {
+		return fmt.Sprintf("#%d", p.ID)
+	}
+	return fmt.Sprintf("#%d %s", p.ID, p.Nickname)
+}
+
+// summary is a one line description of a caught Pokemon, naming the form when it differs from species
+func (p caughtPokemon) summary(species string) string {
+	parts := []string{p.label()}
+	if p.Species != species {
+		parts[0] += " (" + p.Species + ")"
+	}
+	if p.Level > 0 {
+		parts = append(parts, fmt.Sprintf("level %d", p.Level))
+	}
+	if p.Shiny {
+		parts = append(parts, "shiny")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// details describes where, when and how a Pokemon was caught
+func (p caughtPokemon) details() string {
+	line := p.summary(p.Species)
+	if !p.CaughtAt.IsZero() {
+		line += ", caught " + p.CaughtAt.Format("2006-01-02 15:04")
+	}
+	if p.Location != "" {
+		line += " in " + p.Location
+	}
+	return line + ", " + p.Ball
+}
+
+// resolveBall accepts a ball with or without the -ball suffix
+func resolveBall(name string) (string, error) {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, "-ball") {
+		name += "-ball"
+	}
+	if _, exists := balls[name]; !exists {
+		return "", fmt.Errorf("Unknown ball '%s'. Available balls: great-ball, master-ball, poke-ball, ultra-ball", name)
+	}
+	return name, nil
+}
+
+// ballName is how a ball is named when it is thrown
+func ballName(ball string) string {
+	if ball == defaultBall {
+		return "Pokeball"
+	}
+	return strings.ReplaceAll(ball, "-", " ")
+}
+
+// addCaught records a newly caught Pokemon at the level it was encountered, rolling whether it is shiny
+func (c *config) addCaught(name string, pokemon api.Pokemon, ball, nickname string, level int) caughtPokemon {
+	c.nextID++
+	caught := caughtPokemon{
+		ID:       c.nextID,
+		Species:  name,
+		Nickname: nickname,
+		Level:    level,
+		CaughtAt: c.now(),
+		Location: c.location,
+		Ball:     ball,
+		Shiny:    c.rng.Intn(shinyOdds) == 0,
+	}
+	c.pokedex[name] = newDexPokemon(pokemon)
+	c.caught = append(c.caught, caught)
+	return caught
+}
+
+// findCaught looks up a caught Pokemon by #ID or nickname. It returns -1 when
+// the query is neither, so callers can fall back to species names.
+func (c *config) findCaught(query string) (int, error) {
+	if id, isID := strings.CutPrefix(query, "#"); isID {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return -1, fmt.Errorf("Invalid ID '%s'", query)
+		}
+		for i, p := range c.caught {
+			if p.ID == n {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("You have no Pokemon with ID #%d", n)
+	}
+	for i, p := range c.caught {
+		if p.Nickname != "" && strings.EqualFold(p.Nickname, query) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// caughtOf returns every caught Pokemon of one of the given Pokemon names, in the order they were caught
+func (c *config) caughtOf(names ...string) []caughtPokemon {
+	caught := []caughtPokemon{}
+	for _, p := range c.caught {
+		if slices.Contains(names, p.Species) {
+			caught = append(caught, p)
+		}
+	}
+	return caught
+}
+
+// checkNickname makes sure a nickname can be told apart from IDs, Pokemon names and other nicknames
+func (c *config) checkNickname(nickname string, except int) error {
+	if strings.HasPrefix(nickname, "#") || strings.TrimSpace(nickname) != nickname {
+		return fmt.Errorf("Invalid nickname '%s'", nickname)
+	}
+	//Nicknames are looked up before species, so one that reads as a Pokemon would hide it.
+	//Without the name index only the exact names in the Pokedex can be checked.
+	if name, err := c.resolvePokemon(nickname); err == nil {
+		if _, caught := c.pokedex[name]; caught || c.pokemonNames != nil {
+			return fmt.Errorf("Invalid nickname '%s', it would be taken for the Pokemon %s", nickname, name)
+		}
+	}
+	for _, p := range c.caught {
+		if p.ID != except && strings.EqualFold(p.Nickname, nickname) {
+			return fmt.Errorf("%s is already the nickname of #%d", p.Nickname, p.ID)
+		}
+	}
+	return nil
+}
+
+func commandNickname(cfg *config, a args) (result, error) {
+	i, err := cfg.findCaught(a.arg(0))
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		//A species name works when only one of it was caught
+		name := strings.ToLower(a.arg(0))
+		matches := cfg.caughtOf(name)
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("You have not caught %s", a.arg(0))
+		case 1:
+			i, _ = cfg.findCaught(fmt.Sprintf("#%d", matches[0].ID))
+		default:
+			return nil, fmt.Errorf("You have caught %d %s, pick one by ID: %s", len(matches), name, caughtLabels(matches))
+		}
+	}
+
+	caught := &cfg.caught[i]
+	nickname := a.arg(1)
+	if nickname == "" {
+		caught.Nickname = ""
+		return messageResult{Message: fmt.Sprintf("Cleared the nickname of #%d", caught.ID)}, nil
+	}
+	if err := cfg.checkNickname(nickname, caught.ID); err != nil {
+		return nil, err
+	}
+	caught.Nickname = nickname
+	return messageResult{Message: fmt.Sprintf("#%d %s is now called %s", caught.ID, caught.Species, nickname)}, nil
+}
+
+func caughtLabels(caught []caughtPokemon) string {
+	labels := make([]string, len(caught))
+	for i, p := range caught {
+		labels[i] = p.label()
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"path/filepath"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/savefile"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatchInstances(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.rng = rand.New(rand.NewSource(1))
+	cfg.now = func() time.Time { return time.Date(2026, 10, 18, 9, 15, 0, 0, time.UTC) }
+
+	lines := []string{"catch magikarp --ball master", "catch magikarp --ball master --nickname Goldie"}
+	captureOutput(commands, func() {
+		for _, line := range lines {
+			cfg.encounter = &wildEncounter{Pokemon: "magikarp", DisplayName: "Magikarp", Level: 12}
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: unexpected error: %v", line, err)
+			}
+		}
+	})
+	if len(cfg.caught) != 2 || cfg.caught[0].ID != 1 || cfg.caught[1].ID != 2 {
+		t.Fatalf("expected two magikarp with their own IDs, got %v", cfg.caught)
+	}
+	if cfg.caught[1].Nickname != "Goldie" || cfg.caught[1].Ball != "master-ball" || cfg.caught[1].Level == 0 {
+		t.Errorf("expected the nickname, ball and level to be recorded, got %+v", cfg.caught[1])
+	}
+
+	//inspect picks a single Pokemon by ID or nickname, or every one of a species
+	for query, expected := range map[string]int{"#1": 1, "goldie": 1, "magikarp": 2} {
+		res, err := commandInspect(cfg, args{positional: []string{query}})
+		if err != nil {
+			t.Fatalf("inspect %s: unexpected error: %v", query, err)
+		}
+		if caught := res.(pokemonDetails).Caught; len(caught) != expected {
+			t.Errorf("inspect %s: expected %d caught Pokemon, got %v", query, expected, caught)
+		}
+	}
+	if _, err := commandInspect(cfg, args{positional: []string{"#9"}}); err == nil {
+		t.Error("expected an error inspecting an unknown ID")
+	}
+
+	cfg.encounter = &wildEncounter{Pokemon: "magikarp", DisplayName: "Magikarp", Level: 12}
+	errorCases := map[string]string{
+		"nickname magikarp Flop":                  "pick one by ID: #1, #2 Goldie",
+		"nickname #1 goldie":                      "Goldie is already the nickname of #2",
+		"catch magikarp --ball net":               "Unknown ball 'net-ball'",
+		"catch magikarp --nickname #3":            "Invalid nickname '#3'",
+		"catch magikarp --nickname Pikachu":       "Invalid nickname 'Pikachu', it would be taken for the Pokemon pikachu",
+		"nickname #1 25":                          "it would be taken for the Pokemon pikachu",
+		"nickname #1 gyara":                       "it would be taken for the Pokemon gyarados",
+		"catch magikarp --nickname Goldie":        "Goldie is already the nickname of #2",
+		"catch magikarp --ball master --nickname": "Option --nickname requires a value",
+	}
+	for line, expected := range errorCases {
+		if err := commands.run(line); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", line, expected, err)
+		}
+	}
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"nickname #1 Flop", "nickname goldie", "pokedex"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: unexpected error: %v", line, err)
+			}
+		}
+	})
+	expected := "#1 magikarp is now called Flop\nCleared the nickname of #2\nYour Pokedex:\n - magikarp\n"
+	if !strings.HasPrefix(out, expected) || !strings.Contains(out, "#1 Flop, level") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestMigrateV2(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	v2 := map[string]any{
+		"pokedex":  map[string]api.Pokemon{"magikarp": {Name: "magikarp"}, "gastrodon": {Name: "gastrodon"}},
+		"settings": saveSettings{Language: "fr", Output: defaultOutput},
+		"stats":    trainerStats{CatchAttempts: 3, Caught: 2},
+	}
+	if err := savefile.New(path, 2, nil).Save(v2); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := createRegistry().config
+	if err := cfg.loadGame(path); err != nil {
+		t.Fatalf("unexpected error loading a version 2 save: %v", err)
+	}
+	caught, _ := json.Marshal(cfg.caught)
+	if string(caught) != `[{"id":1,"species":"gastrodon","caught_at":"0001-01-01T00:00:00Z","ball":"poke-ball","shiny":false},`+
+		`{"id":2,"species":"magikarp","caught_at":"0001-01-01T00:00:00Z","ball":"poke-ball","shiny":false}]` {
+		t.Errorf("expected a caught Pokemon for each Pokedex entry, got %s", caught)
+	}
+	if cfg.nextID != 2 || cfg.language != "fr" || cfg.stats.Caught != 2 {
+		t.Errorf("expected the rest of the save to be kept, got next ID %d, %s and %+v", cfg.nextID, cfg.language, cfg.stats)
+	}
+
+	//New catches continue after the migrated IDs
+	if added := cfg.addCaught("magikarp", api.Pokemon{Name: "magikarp"}, defaultBall, "", 5); added.ID != 3 {
+		t.Errorf("expected the next ID to be 3, got %d", added.ID)
+	}
+}
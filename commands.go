@@ -2,263 +2,1088 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
 	"pokedexcli/internal/api"
+	"pokedexcli/internal/nameindex"
 	"pokedexcli/internal/pokecache"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 )
 
 type config struct {
-	next      string
-	previous  string
-	pokecache *pokecache.Cache
-	pokedex   map[string]api.Pokemon
-}
+	next        string
+	previous    string
+	pokecache   *pokecache.Cache
+	pokedex     map[string]dexPokemon
+	concurrency int
+	cryDir      string
+	cryPlayer   string
+
+	pokemonNames *nameindex.Index
+	areaNames    *nameindex.Index
+	language     string
+	output       string
+
+	//Results are written to out and the diagnostics of external programs to errOut, catches
+	//roll rng and are timed by now, so tests can swap all of them
+	out    io.Writer
+	errOut io.Writer
+	rng    *rand.Rand
+	now    func() time.Time
+
+	recentAreas []string
+	session     sessionStats
+
+	//User aliases and macros are saved to userConfigPath when it is set
+	userAliases    map[string]string
+	macros         map[string][]string
+	recording      *macroRecording
+	userConfigPath string
+
+	//The profile's Pokedex, settings and stats are autosaved to saveFile when the session ends, "" for never.
+	//savedOutput is the output format kept in the profile, which the -output flag does not change.
+	profile     string
+	saveFile    string
+	savedOutput string
+	stats       trainerStats
+
+	//caught holds every caught Pokemon, while pokedex keeps the data of each kind caught.
+	//location is the area travelled to last and encounter the wild Pokemon met there.
+	caught    []caughtPokemon
+	nextID    int
+	location  string
+	encounter *wildEncounter
 
-type cliCommand struct {
-	name        string
-	description string
-	callback    func(arg string) error
-	config      *config
+	//version limits wild encounters to one game version, any version when empty
+	version string
 }
 
-func createCommandMap() map[string]cliCommand {
+func createRegistry() *commandRegistry {
 	freshCache := pokecache.NewCache(2 * time.Minute)
-	commands := map[string]cliCommand{}
 
 	sharedConfig := &config{
 		next:      "https://pokeapi.co/api/v2/location-area/",
 		previous:  "",
 		pokecache: freshCache,
-		pokedex:   make(map[string]api.Pokemon),
+		pokedex:   make(map[string]dexPokemon),
+		caught:    []caughtPokemon{},
+
+		concurrency: api.DefaultConcurrency,
+		cryDir:      defaultCryDir(),
+		cryPlayer:   os.Getenv("POKEDEX_CRY_PLAYER"),
+		language:    defaultLanguage,
+		output:      defaultOutput,
+		savedOutput: defaultOutput,
+
+		out:    os.Stdout,
+		errOut: os.Stderr,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+
+		userAliases: map[string]string{},
+		macros:      map[string][]string{},
 	}
+	r := newRegistry(sharedConfig)
+	r.onShutdown(func() error {
+		freshCache.Stop()
+		return nil
+	})
 
-	commands["help"] = cliCommand{
+	r.register(cliCommand{
 		name:        "help",
-		description: "Displays all available commands and information about what they do",
-		callback:    func(arg string) error { return commandHelp(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["exit"] = cliCommand{
+		aliases:     []string{"?"},
+		usage:       "help [command]",
+		description: "Displays all available commands, or full usage of a single command",
+		maxArgs:     1,
+		examples:    []string{"help", "help explore"},
+		callback:    func(_ *config, a args) (result, error) { return commandHelp(r, a) },
+		complete:    completeHelp(r),
+	})
+	r.register(cliCommand{
 		name:        "exit",
+		aliases:     []string{"quit"},
+		usage:       "exit",
 		description: "Exit the Pokedex",
 		callback:    commandExit,
-		config:      sharedConfig,
-	}
-	commands["map"] = cliCommand{
+	})
+	r.register(cliCommand{
 		name:        "map",
+		usage:       "map",
 		description: "Display a list of the next 20 location areas in the Pokemon games.",
-		callback:    func(arg string) error { return commandMap(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["mapb"] = cliCommand{
+		callback:    commandMap,
+	})
+	r.register(cliCommand{
 		name:        "mapb",
+		usage:       "mapb",
 		description: "Display a list of the previous 20 location areas in the Pokemon games",
-		callback:    func(arg string) error { return commandMapb(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["explore"] = cliCommand{
+		callback:    commandMapb,
+	})
+	r.register(cliCommand{
 		name:        "explore",
-		description: "Display a list of Pokemon in the provided area. Accepts a single location area as an argument",
-		callback:    func(arg string) error { return commandExplore(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["catch"] = cliCommand{
+		usage:       exploreUsage,
+		description: "Display a list of Pokemon in the provided area",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: []flagSpec{
+			{name: "details", description: "Show each Pokemon's types and base stats"},
+			{name: "table", description: "Show encounter methods, level ranges and chance per game version"},
+			{name: "version", value: "version", description: "Only show encounters in this game version (with --table, defaults to the version setting)"},
+			{name: "method", value: "method", description: "Only show encounters using this method (with --table)"},
+		},
+		examples: []string{
+			"explore pastoria-city-area",
+			"explore pastoria-city-area --details",
+			"explore pastoria-city-area --table --version platinum --method good-rod",
+		},
+		callback: commandExplore,
+		complete: completeArea,
+		input:    itemArea,
+	})
+	r.register(cliCommand{
+		name:        "travel",
+		usage:       "travel <area>",
+		description: "Go to a location area to look for wild Pokemon there",
+		minArgs:     1,
+		maxArgs:     1,
+		examples:    []string{"travel pastoria-city-area"},
+		callback:    commandTravel,
+		complete:    completeArea,
+	})
+	r.register(cliCommand{
+		name:        "walk",
+		aliases:     []string{"encounter"},
+		usage:       "walk",
+		description: "Walk around the current area until a wild Pokemon appears",
+		callback:    commandWalk,
+	})
+	r.register(cliCommand{
+		name:        "fish",
+		usage:       "fish [old|good|super]",
+		description: "Fish with a rod in the current area, old unless another is given",
+		maxArgs:     1,
+		examples:    []string{"fish", "fish good", "fish super"},
+		callback:    commandFish,
+		complete:    completeFish,
+	})
+	r.register(cliCommand{
+		name:        "surf",
+		usage:       "surf",
+		description: "Surf on the water of the current area to look for wild Pokemon",
+		callback:    commandSurf,
+	})
+	r.register(cliCommand{
+		name:        "headbutt",
+		usage:       "headbutt",
+		description: "Headbutt trees in the current area to shake wild Pokemon out",
+		callback:    commandHeadbutt,
+	})
+	r.register(cliCommand{
 		name:        "catch",
-		description: "Try to catch a Pokemon! Takes a Pokemon name as an an argument",
-		callback:    func(arg string) error { return commandCatch(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["inspect"] = cliCommand{
+		usage:       "catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]",
+		description: "Try to catch the wild Pokemon you encountered!",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: []flagSpec{
+			{name: "form", value: "form", description: "Target a specific form, e.g. mega-x"},
+			{name: "ball", value: "ball", description: "Throw a poke, great, ultra or master ball"},
+			{name: "nickname", value: "name", description: "Give the Pokemon a nickname if it is caught"},
+		},
+		examples: []string{"catch magikarp", "catch 129", "catch charizard --form mega-x", "catch gyarados --ball ultra --nickname Tsunami"},
+		callback: commandCatch,
+		complete: completeEncounter,
+		input:    itemPokemon,
+	})
+	r.register(cliCommand{
 		name:        "inspect",
-		description: "See details of a Pokemon you have caught. Takes the name of a Pokemon as an argument",
-		callback:    func(arg string) error { return commandInspect(arg, commands) },
-		config:      sharedConfig,
-	}
-	commands["pokedex"] = cliCommand{
+		usage:       "inspect <pokemon | #id | nickname> [--form <form>]",
+		description: "See details of a Pokemon you have caught",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: []flagSpec{
+			{name: "form", value: "form", description: "Pick a specific form, e.g. mega-x"},
+		},
+		examples: []string{"inspect magikarp", "inspect #2", "inspect Goldie", "inspect charizard --form mega-x"},
+		callback: commandInspect,
+		complete: completeInstance,
+		input:    itemPokemon,
+	})
+	r.register(cliCommand{
+		name:        "nickname",
+		usage:       "nickname <pokemon | #id | nickname> [name]",
+		description: "Give a caught Pokemon a nickname, or clear it when no name is given",
+		minArgs:     1,
+		maxArgs:     2,
+		examples:    []string{"nickname #2 Goldie", "nickname magikarp Flop", "nickname Goldie"},
+		callback:    commandNickname,
+		complete:    completeInstance,
+	})
+	r.register(cliCommand{
+		name:        "where",
+		usage:       "where <pokemon> [--version <version>]",
+		description: "List the location areas where a Pokemon can be found, with the game version, method, level range and chance",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: []flagSpec{
+			{name: "version", value: "version", description: "Only show encounters in this game version (defaults to the version setting)"},
+		},
+		examples: []string{"where magikarp", "where magikarp --version platinum"},
+		callback: commandWhere,
+		complete: completeCaught,
+		input:    itemPokemon,
+	})
+	r.register(cliCommand{
+		name:        "forms",
+		usage:       "forms <species>",
+		description: "List the varieties and forms of a Pokemon species",
+		minArgs:     1,
+		maxArgs:     1,
+		examples:    []string{"forms charizard", "forms shellos"},
+		callback:    commandForms,
+		complete:    completeCaught,
+		input:       itemPokemon,
+	})
+	r.register(cliCommand{
+		name:        "sprite",
+		usage:       "sprite <pokemon> [front|back|shiny] [generation]",
+		description: "Draw a Pokemon's sprite in the terminal",
+		minArgs:     1,
+		maxArgs:     3,
+		examples:    []string{"sprite pikachu", "sprite pikachu shiny", "sprite pikachu back 4"},
+		callback:    commandSprite,
+		complete:    completeSprite,
+		input:       itemPokemon,
+	})
+	r.register(cliCommand{
+		name:        "cry",
+		usage:       "cry <pokemon> [legacy]",
+		description: "Save a Pokemon's cry as an .ogg file and play it with POKEDEX_CRY_PLAYER if set",
+		minArgs:     1,
+		maxArgs:     2,
+		examples:    []string{"cry pikachu", "cry pikachu legacy"},
+		callback:    commandCry,
+		complete:    completeCry,
+		input:       itemPokemon,
+	})
+	r.register(cliCommand{
+		name:        "set",
+		usage:       "set [<setting> <value>]",
+		description: "Change a setting. Without arguments shows the current settings",
+		maxArgs:     2,
+		examples:    []string{"set", "set language fr", "set output json"},
+		callback:    commandSet,
+		complete:    completeSet,
+	})
+	r.register(cliCommand{
 		name:        "pokedex",
+		aliases:     []string{"dex"},
+		usage:       "pokedex",
 		description: "See the list of Pokemon you have caught",
-		callback: func(arg string) error {
-			commandPokedex(commands)
-			return nil
-		},
-		config: sharedConfig,
-	}
-	return commands
+		callback:    commandPokedex,
+	})
+	r.register(cliCommand{
+		name:        "save",
+		usage:       "save [file]",
+		description: "Save your Pokedex. It is also saved automatically when you exit",
+		maxArgs:     1,
+		examples:    []string{"save", "save backup.json"},
+		callback:    commandSave,
+	})
+	r.register(cliCommand{
+		name:        "load",
+		usage:       "load [file]",
+		description: "Replace your Pokedex with the last save, or the one in a file",
+		maxArgs:     1,
+		examples:    []string{"load", "load backup.json"},
+		callback:    commandLoad,
+	})
+	r.register(cliCommand{
+		name:        "profile",
+		usage:       "profile [list | new <name> | switch <name> | delete <name>]",
+		description: "Manage trainer profiles, each with its own Pokedex, settings and statistics",
+		maxArgs:     2,
+		examples:    []string{"profile", "profile new misty", "profile switch misty", "profile delete misty"},
+		callback:    commandProfile,
+		complete:    completeProfile,
+	})
+	r.register(cliCommand{
+		name:        "filter",
+		usage:       "<command> | filter <key>=<value>...",
+		description: "Keep the piped Pokemon or areas matching every condition: name=<pattern>, type=<type> or caught=yes|no",
+		minArgs:     1,
+		maxArgs:     -1,
+		examples:    []string{"pokedex | filter type=water | inspect", "fish good | filter caught=no | catch", "map | filter name=*city*"},
+		callback:    commandFilter,
+		complete:    completeFilter,
+		stream:      true,
+	})
+	r.register(cliCommand{
+		name:        "alias",
+		usage:       "alias [<name> <command>...]",
+		description: "Define a shortcut for a command and its arguments. Without arguments lists your aliases",
+		maxArgs:     -1,
+		examples:    []string{"alias", "alias c catch", `alias home "explore canalave-city-area --details"`},
+		callback:    func(_ *config, a args) (result, error) { return commandAlias(r, a) },
+	})
+	r.register(cliCommand{
+		name:        "unalias",
+		usage:       "unalias <name>",
+		description: "Remove an alias",
+		minArgs:     1,
+		maxArgs:     1,
+		examples:    []string{"unalias c"},
+		callback:    commandUnalias,
+		complete:    completeUnalias,
+	})
+	r.register(cliCommand{
+		name:        "macro",
+		usage:       "macro [list | record <name> | end | delete <name>]",
+		description: "Record a sequence of commands to replay by name, with $1, $2, ... replaced by its arguments",
+		maxArgs:     2,
+		examples:    []string{"macro record hunt", "macro end", "hunt pastoria-city-area magikarp", "macro delete hunt"},
+		callback:    func(_ *config, a args) (result, error) { return commandMacro(r, a) },
+		complete:    completeMacro,
+	})
+	return r
 }
 
-func commandExit(_ string) error {
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	os.Exit(0)
-	return nil
+func commandExit(_ *config, _ args) (result, error) {
+	return nil, errExit
 }
 
-func commandHelp(_ string, commands map[string]cliCommand) error {
-	fmt.Println("Welcome to the Pokedex!")
-	fmt.Println("Usage:")
-	fmt.Println()
+func commandHelp(r *commandRegistry, a args) (result, error) {
+	if name := a.arg(0); name != "" {
+		cmd, exists := r.lookup(strings.ToLower(name))
+		if !exists {
+			return nil, fmt.Errorf("Unknown command '%s'", name)
+		}
+		return cmd.help(), nil
+	}
 
-	keys := make([]string, 0, len(commands))
-	for k := range commands {
-		keys = append(keys, k)
+	list := commandList{}
+	for _, name := range r.names() {
+		list.Commands = append(list.Commands, commandSummary{Name: name, Description: r.commands[name].description})
 	}
+	return list, nil
+}
 
-	// Sort the keys
-	sort.Strings(keys)
+func commandMap(cfg *config, _ args) (result, error) {
+	body, err := api.ApiRequest(cfg.next, cfg.pokecache)
+	if err != nil {
+		return nil, fmt.Errorf("Error making API call: %w", err)
+	}
 
-	// Iterate over the sorted keys and print values
-	for _, k := range keys {
-		fmt.Printf("%s: %s\n", commands[k].name, commands[k].description)
+	var areas api.LocationArea
+	if err := json.Unmarshal(body, &areas); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling JSON: %v", err)
 	}
-	return nil
+
+	cfg.next = areas.Next
+	cfg.previous = areas.Previous
+
+	return newAreaList(areas, cfg), nil
 }
 
-func commandMap(_ string, commands map[string]cliCommand) error {
-	body, err := api.ApiRequest(commands["map"].config.next, commands["map"].config.pokecache)
+func commandMapb(cfg *config, _ args) (result, error) {
+	if cfg.previous == "" {
+		return nil, errors.New("You are already on the first page")
+	}
+	var areas api.LocationArea
+
+	body, err := api.ApiRequest(cfg.previous, cfg.pokecache)
 	if err != nil {
-		return fmt.Errorf("Error making API call: %w", err)
+		return nil, fmt.Errorf("Error making API call: %w", err)
 	}
 
-	var areas api.LocationArea
 	if err := json.Unmarshal(body, &areas); err != nil {
-		return fmt.Errorf("Error unmarshalling JSON: %v", err)
+		return nil, fmt.Errorf("Error unmarshalling JSON: %w", err)
 	}
 
-	for _, area := range areas.Results {
-		fmt.Println(area.Name)
+	cfg.next = areas.Next
+	cfg.previous = areas.Previous
+
+	return newAreaList(areas, cfg), nil
+}
+
+// namedItem is a resource name alongside the name shown for it in the current language
+type namedItem struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// areaList is a page of location areas shown by map and mapb
+type areaList struct {
+	Areas []namedItem `json:"areas"`
+}
+
+func newAreaList(areas api.LocationArea, cfg *config) areaList {
+	names := make([]string, len(areas.Results))
+	for i, area := range areas.Results {
+		names[i] = area.Name
 	}
+	cfg.seeAreas(names...)
 
-	commands["map"].config.next = areas.Next
-	commands["mapb"].config.previous = areas.Previous
+	//Naming the areas would mean downloading each of them, so the page keeps its slugs
+	list := areaList{Areas: make([]namedItem, len(names))}
+	for i, name := range names {
+		list.Areas[i] = namedItem{Name: name, DisplayName: name}
+	}
+	return list
+}
 
+func (l areaList) text(w io.Writer) error {
+	for _, area := range l.Areas {
+		fmt.Fprintln(w, area.DisplayName)
+	}
 	return nil
 }
 
-func commandMapb(_ string, commands map[string]cliCommand) error {
-	if commands["mapb"].config != nil {
-		if commands["mapb"].config.previous == "" {
-			fmt.Println("You are already on the first page.")
-			return nil
+func (l areaList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Areas))
+	for i, area := range l.Areas {
+		rows[i] = []string{area.Name, area.DisplayName}
+	}
+	return []string{"name", "display_name"}, rows
+}
+
+// exploreUsage is shared with commandExplore, which rejects flag combinations the
+// registry cannot check on its own
+const exploreUsage = "explore <area> [--details | --table [--version <version>] [--method <method>]]"
+
+func commandExplore(cfg *config, a args) (result, error) {
+	if a.has("details") && a.has("table") {
+		return nil, fmt.Errorf("Options --details and --table cannot be combined. Usage: %s", exploreUsage)
+	}
+	for _, name := range []string{"version", "method"} {
+		if a.has(name) && !a.has("table") {
+			return nil, fmt.Errorf("Option --%s requires --table. Usage: %s", name, exploreUsage)
 		}
 	}
-	var areas api.LocationArea
 
-	body, err := api.ApiRequest(commands["mapb"].config.previous, commands["mapb"].config.pokecache)
+	arg, err := cfg.resolveArea(a.arg(0))
 	if err != nil {
-		return fmt.Errorf("Error making API call: %w", err)
+		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &areas); err != nil {
-		return fmt.Errorf("Error unmarshalling JSON: %w", err)
+	area, err := fetchArea(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.seeAreas(area.Name)
+	cfg.countExplore(area.Name)
+	header := namedItem{Name: area.Name, DisplayName: cfg.areaDisplayName(area)}
+
+	if a.has("table") {
+		//The version setting applies unless --version picks another
+		version, exists := a.flag("version")
+		if !exists {
+			version = cfg.version
+		}
+		method, _ := a.flag("method")
+		rows := aggregateEncounters(area, strings.ToLower(version), strings.ToLower(method))
+		cfg.localizeEncounters(area, rows)
+		return encounterTable{Area: header, Encounters: rows}, nil
+	}
+
+	explored := exploreResult{Area: header, details: a.has("details")}
+	if area.PokemonEncounters == nil {
+		return explored, nil
 	}
 
-	for _, area := range areas.Results {
-		fmt.Println(area.Name)
+	if !explored.details {
+		names := make([]string, len(area.PokemonEncounters))
+		for i, pokemon := range area.PokemonEncounters {
+			names[i] = pokemon.Pokemon.Name
+		}
+		for i, display := range cfg.localizePokemonNames(names) {
+			explored.Pokemon = append(explored.Pokemon, exploredPokemon{Name: names[i], DisplayName: display})
+		}
+		return explored, nil
 	}
 
-	commands["map"].config.next = areas.Next
-	commands["mapb"].config.previous = areas.Previous
+	//Fetch every Pokemon in the area at once rather than one after another
+	urls := make([]string, len(area.PokemonEncounters))
+	for i, pokemon := range area.PokemonEncounters {
+		urls[i] = pokemon.Pokemon.URL
+	}
+	results, fetchErr := api.FetchMany(urls, cfg.pokecache, cfg.concurrency)
 
+	names := make([]string, len(area.PokemonEncounters))
+	for i, pokemon := range area.PokemonEncounters {
+		names[i] = pokemon.Pokemon.Name
+	}
+	displayNames := cfg.localizePokemonNames(names)
+	for i, pokemon := range area.PokemonEncounters {
+		var mon api.Pokemon
+		if results[i].Err != nil || json.Unmarshal(results[i].Body, &mon) != nil {
+			explored.Pokemon = append(explored.Pokemon, exploredPokemon{
+				Name:        pokemon.Pokemon.Name,
+				DisplayName: displayNames[i],
+				Unavailable: true,
+			})
+			continue
+		}
+		explored.Pokemon = append(explored.Pokemon, exploredPokemon{
+			Name:        mon.Name,
+			DisplayName: displayNames[i],
+			Types:       pokemonTypes(mon),
+			Stats:       pokemonStats(mon),
+		})
+	}
+
+	if fetchErr != nil {
+		return explored, fmt.Errorf("Error fetching Pokemon details: %w", fetchErr)
+	}
+	return explored, nil
+}
+
+// exploreResult lists the Pokemon found in an area, with types and base stats when details are requested
+type exploreResult struct {
+	Area    namedItem         `json:"area"`
+	Pokemon []exploredPokemon `json:"pokemon"`
+	details bool
+}
+
+type exploredPokemon struct {
+	Name        string      `json:"name"`
+	DisplayName string      `json:"display_name"`
+	Types       []string    `json:"types,omitempty"`
+	Stats       []statValue `json:"stats,omitempty"`
+	Unavailable bool        `json:"details_unavailable,omitempty"`
+}
+
+func (e exploreResult) text(w io.Writer) error {
+	fmt.Fprintf(w, "Exploring %s...\n", e.Area.DisplayName)
+	if e.Pokemon == nil {
+		return nil
+	}
+
+	fmt.Fprint(w, "Found Pokemon:\n")
+	for _, pokemon := range e.Pokemon {
+		switch {
+		case !e.details:
+			fmt.Fprintf(w, " - %s\n", pokemon.DisplayName)
+		case pokemon.Unavailable:
+			fmt.Fprintf(w, " - %s (details unavailable)\n", pokemon.DisplayName)
+		default:
+			stats := make([]string, len(pokemon.Stats))
+			for i, stat := range pokemon.Stats {
+				stats[i] = fmt.Sprintf("%s: %d", stat.Name, stat.Value)
+			}
+			fmt.Fprintf(w, " - %s [%s]\n", pokemon.DisplayName, strings.Join(pokemon.Types, "/"))
+			fmt.Fprintf(w, "     %s\n", strings.Join(stats, "  "))
+		}
+	}
 	return nil
 }
 
-func commandExplore(arg string, commands map[string]cliCommand) error {
-	if strings.TrimSpace(arg) == "" {
-		fmt.Print("Please provide a location to check for Pokemon")
+func (e exploreResult) table() ([]string, [][]string) {
+	header := []string{"area", "pokemon", "display_name"}
+	if e.details {
+		header = append(header, "types")
+		header = append(header, statNames...)
+	}
+
+	rows := make([][]string, len(e.Pokemon))
+	for i, pokemon := range e.Pokemon {
+		rows[i] = []string{e.Area.Name, pokemon.Name, pokemon.DisplayName}
+		if e.details {
+			rows[i] = append(rows[i], strings.Join(pokemon.Types, "/"))
+			rows[i] = append(rows[i], statColumns(pokemon.Stats)...)
+		}
+	}
+	return header, rows
+}
+
+// encounterRow aggregates every encounter slot of one Pokemon for a single version and method
+type encounterRow struct {
+	Pokemon     string `json:"pokemon"`
+	DisplayName string `json:"display_name"`
+	Version     string `json:"version"`
+	Method      string `json:"method"`
+	MinLevel    int    `json:"min_level"`
+	MaxLevel    int    `json:"max_level"`
+	Chance      int    `json:"chance"`
+}
+
+func aggregateEncounters(area api.Area, version, method string) []encounterRow {
+	rows := []encounterRow{}
+	for _, encounter := range area.PokemonEncounters {
+		for _, vd := range encounter.VersionDetails {
+			if version != "" && vd.Version.Name != version {
+				continue
+			}
+
+			//Keep methods in the order they first appear for this version
+			index := map[string]int{}
+			start := len(rows)
+			for _, detail := range vd.EncounterDetails {
+				if method != "" && detail.Method.Name != method {
+					continue
+				}
+				i, exists := index[detail.Method.Name]
+				if !exists {
+					rows = append(rows, encounterRow{
+						Pokemon:     encounter.Pokemon.Name,
+						DisplayName: encounter.Pokemon.Name,
+						Version:     vd.Version.Name,
+						Method:      detail.Method.Name,
+						MinLevel:    detail.MinLevel,
+						MaxLevel:    detail.MaxLevel,
+					})
+					i = len(rows) - 1 - start
+					index[detail.Method.Name] = i
+				}
+				row := &rows[start+i]
+				row.MinLevel = min(row.MinLevel, detail.MinLevel)
+				row.MaxLevel = max(row.MaxLevel, detail.MaxLevel)
+				row.Chance += detail.Chance
+			}
+		}
+	}
+	return rows
+}
+
+// localizeEncounters sets the display name of every Pokemon in the rows, looking
+// up only the Pokemon of the area that are left after filtering
+func (c *config) localizeEncounters(area api.Area, rows []encounterRow) {
+	names := []string{}
+	for _, pokemon := range area.PokemonEncounters {
+		if slices.ContainsFunc(rows, func(row encounterRow) bool { return row.Pokemon == pokemon.Pokemon.Name }) {
+			names = append(names, pokemon.Pokemon.Name)
+		}
+	}
+
+	displayNames := map[string]string{}
+	for i, display := range c.localizePokemonNames(names) {
+		displayNames[names[i]] = display
+	}
+	for i := range rows {
+		rows[i].DisplayName = displayNames[rows[i].Pokemon]
+	}
+}
+
+// encounterTable is the per version and method breakdown shown by explore --table
+type encounterTable struct {
+	Area       namedItem      `json:"area"`
+	Encounters []encounterRow `json:"encounters"`
+}
+
+func (t encounterTable) text(w io.Writer) error {
+	fmt.Fprintf(w, "Exploring %s...\n", t.Area.DisplayName)
+	if len(t.Encounters) == 0 {
+		fmt.Fprintln(w, "No encounters match the given filters")
 		return nil
 	}
-	fmt.Printf("Exploring %s...\n", arg)
 
-	var area api.Area
-	body, err := api.ApiRequest("https://pokeapi.co/api/v2/location-area/"+arg, commands["explore"].config.pokecache)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "POKEMON\tVERSION\tMETHOD\tLEVELS\tCHANCE")
+	for _, row := range t.Encounters {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d%%\n", row.DisplayName, row.Version, row.Method, levelRange(row.MinLevel, row.MaxLevel), row.Chance)
+	}
+	return tw.Flush()
+}
+
+func (t encounterTable) table() ([]string, [][]string) {
+	rows := make([][]string, len(t.Encounters))
+	for i, row := range t.Encounters {
+		rows[i] = []string{t.Area.Name, row.Pokemon, row.DisplayName, row.Version, row.Method,
+			strconv.Itoa(row.MinLevel), strconv.Itoa(row.MaxLevel), strconv.Itoa(row.Chance)}
+	}
+	return []string{"area", "pokemon", "display_name", "version", "method", "min_level", "max_level", "chance"}, rows
+}
+
+func pokemonTypes(mon api.Pokemon) []string {
+	types := make([]string, len(mon.Types))
+	for i, t := range mon.Types {
+		types[i] = t.Type.Name
+	}
+	return types
+}
+
+func pokemonStats(mon api.Pokemon) []statValue {
+	stats := make([]statValue, len(mon.Stats))
+	for i, stat := range mon.Stats {
+		stats[i] = statValue{Name: stat.Stat.Name, Value: stat.BaseStat}
+	}
+	return stats
+}
+
+func commandCatch(cfg *config, a args) (result, error) {
+	form, _ := a.flag("form")
+	arg, err := cfg.resolvePokemon(a.arg(0))
 	if err != nil {
-		if strings.Contains(err.Error(), "status code: 404") {
-			return fmt.Errorf("Area '%s' does not exist. Please check spelling and try again", arg)
+		return nil, err
+	}
+	if form != "" {
+		arg, err = resolveVariety(arg, form, cfg)
+		if err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("Error exploring area: %w", err)
 	}
-
-	if err := json.Unmarshal(body, &area); err != nil {
-		return fmt.Errorf("Error unmarshalling JSON: %w", err)
+	if err := cfg.checkEncounter(arg); err != nil {
+		return nil, err
 	}
 
-	if area.PokemonEncounters != nil {
-		fmt.Print("Found Pokemon:\n")
-		for _, pokemon := range area.PokemonEncounters {
-			fmt.Printf(" - %s\n", pokemon.Pokemon.Name)
+	ball := defaultBall
+	if name, _ := a.flag("ball"); name != "" {
+		if ball, err = resolveBall(name); err != nil {
+			return nil, err
 		}
 	}
+	nickname, _ := a.flag("nickname")
+	if nickname != "" {
+		if err := cfg.checkNickname(nickname, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	pokemon, err := fetchPokemon(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	catch := catchAttempt(cfg.rng, pokemon.BaseExperience, balls[ball])
+	cfg.countCatch(arg, catch)
+	caught := catchResult{
+		Pokemon: namedItem{Name: arg, DisplayName: cfg.pokemonDisplayName(pokemon)},
+		Ball:    ball,
+		Caught:  catch,
+	}
+	if catch {
+		instance := cfg.addCaught(arg, pokemon, ball, nickname, cfg.encounter.Level)
+		caught.Instance = &instance
+		cfg.encounter = nil
+	}
+	return caught, nil
+}
+
+// catchResult is the outcome of throwing a Pokeball, and the Pokemon caught if it worked
+type catchResult struct {
+	Pokemon  namedItem      `json:"pokemon"`
+	Ball     string         `json:"ball"`
+	Caught   bool           `json:"caught"`
+	Instance *caughtPokemon `json:"instance,omitempty"`
+}
+
+func (c catchResult) text(w io.Writer) error {
+	fmt.Fprintf(w, "Throwing a %s at %s...\n", ballName(c.Ball), c.Pokemon.DisplayName)
+	if c.Caught {
+		fmt.Fprintf(w, "%s was caught!\n", c.Pokemon.DisplayName)
+		fmt.Fprintf(w, "It was added to your Pokedex as %s\n", c.Instance.summary(c.Instance.Species))
+		fmt.Fprintln(w, "You may now inspect it with the inspect command")
+	} else {
+		fmt.Fprintf(w, "%s escaped!\n", c.Pokemon.DisplayName)
+	}
 	return nil
 }
 
-func commandCatch(arg string, commands map[string]cliCommand) error {
-	fmt.Printf("Throwing a Pokeball at %s...\n", arg)
+func (c catchResult) table() ([]string, [][]string) {
+	id := ""
+	if c.Instance != nil {
+		id = strconv.Itoa(c.Instance.ID)
+	}
+	return []string{"pokemon", "display_name", "ball", "caught", "id"},
+		[][]string{{c.Pokemon.Name, c.Pokemon.DisplayName, c.Ball, strconv.FormatBool(c.Caught), id}}
+}
 
+// fetchPokemon requests a Pokemon by its resolved name
+func fetchPokemon(name string, cfg *config) (api.Pokemon, error) {
 	var pokemon api.Pokemon
-	body, err := api.ApiRequest("https://pokeapi.co/api/v2/pokemon/"+arg, commands["catch"].config.pokecache)
+	body, err := api.ApiRequest("https://pokeapi.co/api/v2/pokemon/"+name, cfg.pokecache)
 	if err != nil {
 		if strings.Contains(err.Error(), "status code: 404") {
-			return fmt.Errorf("Pokemon '%s' does not exist. Please check spelling and try again", arg)
+			return pokemon, notFoundError("Pokemon", name, err)
 		}
-		return fmt.Errorf("Error trying to catch %s: %w", arg, err)
+		return pokemon, fmt.Errorf("Error looking up %s: %w", name, err)
 	}
 
 	if err := json.Unmarshal(body, &pokemon); err != nil {
-		return fmt.Errorf("Error unmarshalling JSON: %w", err)
+		return pokemon, fmt.Errorf("Error unmarshalling JSON: %w", err)
 	}
+	return pokemon, nil
+}
 
-	catch := catchAttempt(pokemon.BaseExperience)
+func catchAttempt(rng *rand.Rand, baseEXP int, ballBonus float64) bool {
+	catch_rate := (1.95 - 0.279*math.Log(float64(baseEXP))) * ballBonus
+	rand_num := rng.Float64()
+	return catch_rate > rand_num
+}
 
-	if catch {
-		commands["catch"].config.pokedex[arg] = pokemon
-		fmt.Printf("%s was caught!\n", arg)
-		fmt.Println("You may now inspect it with the inspect command")
-	} else {
-		fmt.Printf("%s escaped!\n", arg)
+func commandInspect(cfg *config, a args) (result, error) {
+	//A single caught Pokemon can be picked by #ID or nickname
+	i, err := cfg.findCaught(a.arg(0))
+	if err != nil {
+		return nil, err
+	}
+	if i >= 0 {
+		caught := cfg.caught[i]
+		details := newPokemonDetails(cfg.pokedex[caught.Species], cfg)
+		details.Caught = []caughtPokemon{caught}
+		return details, nil
+	}
+
+	arg := strings.ToLower(a.arg(0))
+	if form, _ := a.flag("form"); form != "" {
+		name, err := resolveVariety(arg, form, cfg)
+		if err != nil {
+			return nil, err
+		}
+		arg = name
+	}
+	if _, exists := cfg.pokedex[arg]; !exists {
+		//Accept dex numbers and prefixes too, but keep the original name if it cannot be resolved
+		if name, err := cfg.resolvePokemon(arg); err == nil {
+			arg = name
+		}
+	}
+	val, exists := cfg.pokedex[arg]
+	if !exists {
+		return nil, fmt.Errorf("You have not caught %s yet", arg)
+	}
+	details := newPokemonDetails(val, cfg)
+	details.Caught = cfg.caughtOf(arg)
+	return details, nil
+}
+
+// pokemonDetails is everything inspect shows about a caught Pokemon
+type pokemonDetails struct {
+	Name        string          `json:"name"`
+	DisplayName string          `json:"display_name"`
+	Description string          `json:"description,omitempty"`
+	Height      int             `json:"height"`
+	Weight      int             `json:"weight"`
+	Stats       []statValue     `json:"stats"`
+	Types       []string        `json:"types"`
+	Caught      []caughtPokemon `json:"caught"`
+}
+
+func newPokemonDetails(mon dexPokemon, cfg *config) pokemonDetails {
+	details := pokemonDetails{
+		Name:        mon.Name,
+		DisplayName: mon.Name,
+		Height:      mon.Height,
+		Weight:      mon.Weight,
+		Stats:       mon.Stats,
+		Types:       mon.Types,
+	}
+	if species, err := fetchSpeciesByURL(mon.Species.URL, cfg); err == nil {
+		details.DisplayName = speciesDisplayName(mon.Name, species, cfg.language)
+		details.Description, _ = api.LocalizedFlavorText(species.FlavorTextEntries, cfg.language)
+	}
+	return details
+}
+
+func (p pokemonDetails) text(w io.Writer) error {
+	fmt.Fprintf(w, "Name: %s\n", p.DisplayName)
+	if p.Description != "" {
+		fmt.Fprintf(w, "Description: %s\n", p.Description)
+	}
+	fmt.Fprintf(w, "Height: %d\n", p.Height)
+	fmt.Fprintf(w, "Weight: %d\n", p.Weight)
+	fmt.Fprintf(w, "Stats:\n")
+	for _, stat := range p.Stats {
+		fmt.Fprintf(w, " -%s: %d\n", stat.Name, stat.Value)
+	}
+	fmt.Fprintf(w, "Types:\n")
+	for _, t := range p.Types {
+		fmt.Fprintf(w, " - %s\n", t)
+	}
+	if len(p.Caught) > 0 {
+		fmt.Fprintf(w, "Caught:\n")
+		for _, caught := range p.Caught {
+			fmt.Fprintf(w, " - %s\n", caught.details())
+		}
+	}
+	return nil
+}
+
+func (p pokemonDetails) table() ([]string, [][]string) {
+	ids := make([]string, len(p.Caught))
+	for i, caught := range p.Caught {
+		ids[i] = strconv.Itoa(caught.ID)
+	}
+	header := append([]string{"name", "display_name", "description", "height", "weight", "types", "caught"}, statNames...)
+	row := []string{p.Name, p.DisplayName, p.Description, strconv.Itoa(p.Height), strconv.Itoa(p.Weight), strings.Join(p.Types, "/"), strings.Join(ids, "/")}
+	return header, [][]string{append(row, statColumns(p.Stats)...)}
+}
+
+func commandPokedex(cfg *config, _ args) (result, error) {
+	species, groups := groupBySpecies(cfg.pokedex)
+	urls := make([]string, len(species))
+	for i, s := range species {
+		urls[i] = cfg.pokedex[groups[s][0]].Species.URL
+	}
+
+	dex := pokedexResult{Species: []pokedexEntry{}}
+	for i, display := range cfg.localizeSpeciesNames(species, urls) {
+		dex.Species = append(dex.Species, pokedexEntry{
+			Name:        species[i],
+			DisplayName: display,
+			Pokemon:     groups[species[i]],
+			Caught:      cfg.caughtOf(groups[species[i]]...),
+		})
 	}
+	return dex, nil
+}
 
+// pokedexResult is every caught Pokemon grouped under its species
+type pokedexResult struct {
+	Species []pokedexEntry `json:"species"`
+}
+
+type pokedexEntry struct {
+	Name        string          `json:"name"`
+	DisplayName string          `json:"display_name"`
+	Pokemon     []string        `json:"pokemon"`
+	Caught      []caughtPokemon `json:"caught"`
+}
+
+func (d pokedexResult) text(w io.Writer) error {
+	fmt.Fprintln(w, "Your Pokedex:")
+	for _, entry := range d.Species {
+		fmt.Fprintf(w, " - %s\n", entry.DisplayName)
+		//Each caught Pokemon is listed under its species, with the form named if it has one
+		for _, caught := range entry.Caught {
+			fmt.Fprintf(w, "     %s\n", caught.summary(entry.Name))
+		}
+	}
 	return nil
 }
 
-func catchAttempt(baseEXP int) bool {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	catch_rate := 1.95 - 0.279*math.Log(float64(baseEXP))
-	rand_num := rng.Float64()
-	return catch_rate > rand_num
+func (d pokedexResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range d.Species {
+		for _, caught := range entry.Caught {
+			rows = append(rows, []string{entry.Name, entry.DisplayName, caught.Species, strconv.Itoa(caught.ID),
+				caught.Nickname, strconv.Itoa(caught.Level), strconv.FormatBool(caught.Shiny)})
+		}
+	}
+	return []string{"species", "display_name", "pokemon", "id", "nickname", "level", "shiny"}, rows
 }
 
-func commandInspect(arg string, commands map[string]cliCommand) error {
-	val, exists := commands["inspect"].config.pokedex[arg]
+func commandWhere(cfg *config, a args) (result, error) {
+	arg, err := cfg.resolvePokemon(a.arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	pokemon, err := fetchPokemon(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var encounters []api.LocationAreaEncounter
+	body, err := api.ApiRequest(pokemon.LocationAreaEncounters, cfg.pokecache)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching encounters for %s: %w", arg, err)
+	}
+
+	if err := json.Unmarshal(body, &encounters); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling JSON: %w", err)
+	}
+
+	//As with explore --table, the version setting applies unless --version picks another
+	version, exists := a.flag("version")
 	if !exists {
-		fmt.Printf("You have not caught %s yet!\n", arg)
+		version = cfg.version
+	}
+	where := whereResult{
+		Pokemon: namedItem{Name: pokemon.Name, DisplayName: cfg.pokemonDisplayName(pokemon)},
+		Version: strings.ToLower(version),
+		Areas:   []whereArea{},
+	}
+	if len(encounters) == 0 {
+		return where, nil
+	}
+
+	names := make([]string, len(encounters))
+	for i, encounter := range encounters {
+		names[i] = encounter.LocationArea.Name
+	}
+	cfg.seeAreas(names...)
+
+	//Like map, areas are not downloaded just to name them
+	for i, name := range names {
+		area := whereArea{Name: name, DisplayName: name}
+		for _, vd := range encounters[i].VersionDetails {
+			if where.Version != "" && vd.Version.Name != where.Version {
+				continue
+			}
+			for _, detail := range vd.EncounterDetails {
+				area.Encounters = append(area.Encounters, encounterRow{
+					Pokemon:  pokemon.Name,
+					Version:  vd.Version.Name,
+					Method:   detail.Method.Name,
+					MinLevel: detail.MinLevel,
+					MaxLevel: detail.MaxLevel,
+					Chance:   detail.Chance,
+				})
+			}
+		}
+		//Areas the Pokemon only appears in for other versions are left out
+		if len(area.Encounters) > 0 {
+			where.Areas = append(where.Areas, area)
+		}
+	}
+	return where, nil
+}
+
+// whereResult lists every area a Pokemon can be found in and how
+type whereResult struct {
+	Pokemon namedItem   `json:"pokemon"`
+	Version string      `json:"version,omitempty"`
+	Areas   []whereArea `json:"areas"`
+}
+
+type whereArea struct {
+	Name        string         `json:"name"`
+	DisplayName string         `json:"display_name"`
+	Encounters  []encounterRow `json:"encounters"`
+}
+
+func (r whereResult) text(w io.Writer) error {
+	if len(r.Areas) == 0 && r.Version != "" {
+		fmt.Fprintf(w, "%s cannot be found in the wild in %s\n", r.Pokemon.DisplayName, r.Version)
 		return nil
 	}
-	printPokemon(val)
+	if len(r.Areas) == 0 {
+		fmt.Fprintf(w, "%s cannot be found in the wild\n", r.Pokemon.DisplayName)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s can be found in:\n", r.Pokemon.DisplayName)
+	for _, area := range r.Areas {
+		fmt.Fprintf(w, " - %s\n", area.DisplayName)
+		for _, e := range area.Encounters {
+			fmt.Fprintf(w, "     %s: %s, level %s, %d%% chance\n",
+				e.Version, e.Method, levelRange(e.MinLevel, e.MaxLevel), e.Chance)
+		}
+	}
 	return nil
 }
 
-func printPokemon(mon api.Pokemon) {
-	fmt.Printf("Name: %s\n", mon.Name)
-	fmt.Printf("Height: %d\n", mon.Height)
-	fmt.Printf("Weight: %d\n", mon.Weight)
-	fmt.Printf("Stats:\n")
-	for _, stat := range mon.Stats {
-		fmt.Printf(" -%s: %d\n", stat.Stat.Name, stat.BaseStat)
-	}
-	fmt.Printf("Types:\n")
-	for _, t := range mon.Types {
-		fmt.Printf(" - %s\n", t.Type.Name)
+func (r whereResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, area := range r.Areas {
+		for _, e := range area.Encounters {
+			rows = append(rows, []string{r.Pokemon.Name, area.Name, e.Version, e.Method,
+				strconv.Itoa(e.MinLevel), strconv.Itoa(e.MaxLevel), strconv.Itoa(e.Chance)})
+		}
 	}
+	return []string{"pokemon", "area", "version", "method", "min_level", "max_level", "chance"}, rows
 }
 
-func commandPokedex(commands map[string]cliCommand) {
-	fmt.Println("Your Pokedex:")
-	for _, pokemon := range commands["pokedex"].config.pokedex {
-		fmt.Printf(" - %s\n", pokemon.Name)
+func levelRange(minLevel, maxLevel int) string {
+	if minLevel == maxLevel {
+		return fmt.Sprintf("%d", minLevel)
 	}
+	return fmt.Sprintf("%d-%d", minLevel, maxLevel)
 }
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"pokedexcli/internal/api"
+	"strings"
+	"testing"
+)
+
+// Run `go test -run TestCommand -record` to refresh the cassette against the live PokeAPI
+var record = flag.Bool("record", false, "record PokeAPI interactions to testdata/cassettes")
+
+func useCassette(t *testing.T, name string) {
+	t.Helper()
+	mode := api.ModeReplayOnly
+	if *record {
+		mode = api.ModeRecord
+	}
+
+	rec, err := api.NewRecorder("testdata/cassettes/"+name+".json", mode, nil)
+	if err != nil {
+		t.Fatalf("unexpected error loading cassette: %v", err)
+	}
+	api.SetTransport(rec)
+	t.Cleanup(func() {
+		api.SetTransport(nil)
+		if err := rec.Save(); err != nil {
+			t.Errorf("unexpected error saving cassette: %v", err)
+		}
+		//Commands often fall back quietly when a request fails, so a missing recording has to be caught here
+		for _, request := range rec.Unrecorded() {
+			t.Errorf("request missing from the %s cassette: %s", name, request)
+		}
+	})
+}
+
+// captureOutput returns everything the commands write while fn runs
+func captureOutput(commands *commandRegistry, fn func()) string {
+	out := &bytes.Buffer{}
+	previous := commands.config.out
+	commands.config.out = out
+	defer func() { commands.config.out = previous }()
+
+	fn()
+	return out.String()
+}
+
+func TestCommandMap(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("map") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 20 {
+		t.Errorf("expected 20 location areas, got %d", len(lines))
+	}
+	if lines[0] != "canalave-city-area" {
+		t.Errorf("expected first area canalave-city-area, got %s", lines[0])
+	}
+	if commands.config.next != "https://pokeapi.co/api/v2/location-area/?offset=20&limit=20" {
+		t.Errorf("expected next page to be stored, got %s", commands.config.next)
+	}
+}
+
+func TestCommandExplore(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("explore pastoria-city-area") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"tentacool", "magikarp", "gyarados"} {
+		if !strings.Contains(out, " - "+name+"\n") {
+			t.Errorf("expected %s in explore output, got:\n%s", name, out)
+		}
+	}
+}
+
+func TestCommandCatch(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.encounter = &wildEncounter{Pokemon: "magikarp", DisplayName: "Magikarp"}
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("catch magikarp") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, caught := commands.config.pokedex["magikarp"]
+	switch {
+	case strings.Contains(out, "Magikarp was caught!"):
+		if !caught {
+			t.Errorf("expected magikarp in the pokedex after catching it")
+		}
+	case strings.Contains(out, "Magikarp escaped!"):
+		if caught {
+			t.Errorf("expected magikarp to not be in the pokedex after it escaped")
+		}
+	default:
+		t.Errorf("unexpected catch output:\n%s", out)
+	}
+}
+
+func TestCommandCatchUnknownPokemon(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	captureOutput(commands, func() { err = commands.run("catch pikachuu") })
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected does not exist error, got: %v", err)
+	}
+}
+
+func TestCommandExploreDetails(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("explore pastoria-city-area --details") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		" - tentacool [water/poison]\n",
+		"     hp: 40  attack: 40  defense: 35  special-attack: 50  special-defense: 100  speed: 70\n",
+		" - gastrodon [water/ground]\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in explore output, got:\n%s", want, out)
+		}
+	}
+
+	// Results must come back in encounter order even though they are fetched concurrently
+	if strings.Index(out, "tentacool") > strings.Index(out, "gastrodon") {
+		t.Errorf("expected details in encounter order, got:\n%s", out)
+	}
+}
+
+func TestCommandWhere(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("where magikarp") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Magikarp can be found in:\n",
+		" - pastoria-city-area\n",
+		"     diamond: old-rod, level 3-15, 100% chance\n",
+		"     heartgold: good-rod, level 20, 65% chance\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in where output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCommandWhereVersion(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("where magikarp --version heartgold") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "     heartgold: good-rod, level 20, 65% chance\n") || strings.Contains(out, "diamond") {
+		t.Errorf("expected only heartgold encounters, got:\n%s", out)
+	}
+
+	//The version setting is the default, and areas without encounters in it are left out
+	out = captureOutput(commands, func() {
+		for _, line := range []string{"set version diamond", "where magikarp"} {
+			if err = commands.run(line); err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "     diamond: old-rod, level 3-15, 100% chance\n") || strings.Contains(out, "heartgold") {
+		t.Errorf("expected only diamond encounters, got:\n%s", out)
+	}
+
+	out = captureOutput(commands, func() { err = commands.run("where magikarp --version scarlet") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Magikarp cannot be found in the wild in scarlet\n" {
+		t.Errorf("unexpected where output:\n%s", out)
+	}
+}
+
+func TestCommandWhereNotInWild(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("where gastrodon") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Gastrodon cannot be found in the wild\n" {
+		t.Errorf("unexpected where output:\n%s", out)
+	}
+}
+
+func TestCommandExploreTable(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() {
+		err = commands.run("explore pastoria-city-area --table --version platinum --method good-rod")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Exploring Pastoria City...\n" +
+		"POKEMON   VERSION   METHOD    LEVELS  CHANCE\n" +
+		"magikarp  platinum  good-rod  10-25   55%\n" +
+		"gyarados  platinum  good-rod  10-25   5%\n"
+	if out != expected {
+		t.Errorf("unexpected table output:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestAggregateEncounters(t *testing.T) {
+	var area api.Area
+	body := `{"pokemon_encounters":[{"pokemon":{"name":"geodude"},"version_details":[
+		{"version":{"name":"diamond"},"encounter_details":[
+			{"chance":20,"min_level":5,"max_level":5,"method":{"name":"walk"}},
+			{"chance":10,"min_level":7,"max_level":8,"method":{"name":"walk"}},
+			{"chance":5,"min_level":3,"max_level":4,"method":{"name":"rock-smash"}}]},
+		{"version":{"name":"pearl"},"encounter_details":[
+			{"chance":30,"min_level":5,"max_level":6,"method":{"name":"walk"}}]}]}]}`
+	if err := json.Unmarshal([]byte(body), &area); err != nil {
+		t.Fatalf("failed to parse area: %v", err)
+	}
+
+	rows := aggregateEncounters(area, "diamond", "")
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Method != "walk" || rows[0].Chance != 30 || rows[0].MinLevel != 5 || rows[0].MaxLevel != 8 {
+		t.Errorf("unexpected walk row: %+v", rows[0])
+	}
+	if rows[1].Method != "rock-smash" || rows[1].Chance != 5 {
+		t.Errorf("unexpected rock-smash row: %+v", rows[1])
+	}
+
+	if rows := aggregateEncounters(area, "", "walk"); len(rows) != 2 {
+		t.Errorf("expected a walk row per version, got %d", len(rows))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// maxRecentAreas is how many location areas are remembered for completion
+const maxRecentAreas = 100
+
+// defaultHistoryFile is where REPL history is kept unless POKEDEX_HISTORY_FILE says otherwise
+func defaultHistoryFile() string {
+	if path := os.Getenv("POKEDEX_HISTORY_FILE"); path != "" {
+		return path
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "pokedexcli", "history")
+	}
+	return ".pokedex_history"
+}
+
+// seeAreas remembers location areas shown to the user, most recent first
+func (c *config) seeAreas(names ...string) {
+	recent := make([]string, 0, len(names)+len(c.recentAreas))
+	seen := map[string]bool{}
+	for _, name := range append(append([]string{}, names...), c.recentAreas...) {
+		if !seen[name] {
+			seen[name] = true
+			recent = append(recent, name)
+		}
+	}
+	c.recentAreas = recent[:min(len(recent), maxRecentAreas)]
+}
+
+func completeCaught(cfg *config, prev []string) []string {
+	if len(prev) != 0 {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.pokedex))
+	for name := range cfg.pokedex {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeInstance offers caught Pokemon by name, #ID and nickname
+func completeInstance(cfg *config, prev []string) []string {
+	if len(prev) != 0 {
+		return nil
+	}
+	names := completeCaught(cfg, prev)
+	for _, p := range cfg.caught {
+		names = append(names, fmt.Sprintf("#%d", p.ID))
+		if p.Nickname != "" {
+			names = append(names, p.Nickname)
+		}
+	}
+	return names
+}
+
+func completeArea(cfg *config, prev []string) []string {
+	if len(prev) != 0 {
+		return nil
+	}
+	return cfg.recentAreas
+}
+
+func completeSprite(cfg *config, prev []string) []string {
+	if len(prev) == 0 {
+		return completeCaught(cfg, prev)
+	}
+	if len(prev) > 2 {
+		return nil
+	}
+	return []string{"front", "back", "shiny"}
+}
+
+func completeCry(cfg *config, prev []string) []string {
+	if len(prev) == 0 {
+		return completeCaught(cfg, prev)
+	}
+	if len(prev) == 1 {
+		return []string{"legacy"}
+	}
+	return nil
+}
+
+func completeSet(_ *config, prev []string) []string {
+	if len(prev) == 0 {
+		return []string{"language", "output", "version"}
+	}
+	if len(prev) == 1 && prev[0] == "language" {
+		return languages
+	}
+	if len(prev) == 1 && prev[0] == "output" {
+		return outputFormats()
+	}
+	if len(prev) == 1 && prev[0] == "version" {
+		return append([]string{anyVersion}, versions...)
+	}
+	return nil
+}
+
+// completeHelp offers command names, ignoring aliases to keep the list short
+func completeHelp(r *commandRegistry) func(cfg *config, prev []string) []string {
+	return func(_ *config, prev []string) []string {
+		if len(prev) != 0 {
+			return nil
+		}
+		return r.names()
+	}
+}
+
+// completeUnalias offers the user's aliases
+func completeUnalias(cfg *config, prev []string) []string {
+	if len(prev) != 0 {
+		return nil
+	}
+	return sortedKeys(cfg.userAliases)
+}
+
+// completeMacro offers the macro actions, then saved macros to delete
+func completeMacro(cfg *config, prev []string) []string {
+	switch {
+	case len(prev) == 0:
+		return []string{"delete", "end", "list", "record"}
+	case len(prev) == 1 && prev[0] == "delete":
+		return sortedKeys(cfg.macros)
+	}
+	return nil
+}
+
+// pokemonTypeNames are the eighteen Pokemon types, offered when filtering by type
+var pokemonTypeNames = []string{"bug", "dark", "dragon", "electric", "fairy", "fighting", "fire", "flying", "ghost",
+	"grass", "ground", "ice", "normal", "poison", "psychic", "rock", "steel", "water"}
+
+// completeFilter offers whole conditions, since a name pattern cannot be guessed
+func completeFilter(_ *config, _ []string) []string {
+	candidates := []string{"caught=no", "caught=yes"}
+	for _, t := range pokemonTypeNames {
+		candidates = append(candidates, "type="+t)
+	}
+	return candidates
+}
+
+// completeProfile offers the profile actions, then profile names to switch to or delete
+func completeProfile(cfg *config, prev []string) []string {
+	switch {
+	case len(prev) == 0:
+		return []string{"delete", "list", "new", "switch"}
+	case len(prev) == 1 && (prev[0] == "switch" || prev[0] == "delete"):
+		names := []string{}
+		for _, name := range profileNames(cfg) {
+			if name != cfg.profile {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
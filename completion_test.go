@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegistryComplete(t *testing.T) {
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.seeAreas("canalave-city-area", "pastoria-city-area")
+	cfg.seeAreas("eterna-city-area", "pastoria-city-area")
+	cfg.pokedex["magikarp"] = dexPokemon{Name: "magikarp"}
+	cfg.pokedex["gyarados"] = dexPokemon{Name: "gyarados"}
+	cfg.encounter = &wildEncounter{Pokemon: "tentacool"}
+
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{"", commands.namesAndAliases()},
+		{"ex", commands.namesAndAliases()},
+		{"explore ", []string{"eterna-city-area", "pastoria-city-area", "canalave-city-area"}},
+		{"EXPLORE pas", []string{"eterna-city-area", "pastoria-city-area", "canalave-city-area"}},
+		{"explore pastoria-city-area --", []string{"--details", "--table", "--version", "--method"}},
+		{"explore pastoria-city-area --table --version ", nil},
+		{"explore pastoria-city-area ", nil},
+		{"inspect ", []string{"gyarados", "magikarp"}},
+		{"catch --ball ultra ", []string{"tentacool"}},
+		{"catch --form alola ", []string{"tentacool"}},
+		{"travel ", []string{"eterna-city-area", "pastoria-city-area", "canalave-city-area"}},
+		{"sprite magikarp ", []string{"front", "back", "shiny"}},
+		{"cry magikarp ", []string{"legacy"}},
+		{"set language ", languages},
+		{"help ", commands.names()},
+		{"pokedex | ", commands.namesAndAliases()},
+		{"pokedex | filter type=water | inspect ", []string{"gyarados", "magikarp"}},
+		{"teleport ", nil},
+	}
+	for _, c := range cases {
+		actual := commands.complete(c.line)
+		if !slices.Equal(actual, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.line, c.expected, actual)
+		}
+	}
+}
+
+func TestCommandMapRemembersAreas(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	captureOutput(commands, func() { commands.run("map") })
+	if len(commands.config.recentAreas) != 20 || commands.config.recentAreas[0] != "canalave-city-area" {
+		t.Errorf("expected the listed areas to be remembered, got %v", commands.config.recentAreas)
+	}
+
+	captureOutput(commands, func() { commands.run("explore pastoria-city-area") })
+	if commands.config.recentAreas[0] != "pastoria-city-area" || len(commands.config.recentAreas) != 20 {
+		t.Errorf("expected the explored area to move to the front, got %v", commands.config.recentAreas)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pokedexcli/internal/api"
+	"strconv"
+	"strings"
+)
+
+// defaultCryDir is where cries are saved unless POKEDEX_CRY_DIR says otherwise
+func defaultCryDir() string {
+	if dir := os.Getenv("POKEDEX_CRY_DIR"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "pokedexcli", "cries")
+	}
+	return "cries"
+}
+
+func commandCry(cfg *config, a args) (result, error) {
+	name := a.arg(0)
+	legacy := strings.ToLower(a.arg(1)) == "legacy"
+
+	name, err := cfg.resolvePokemon(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pokemon, err := fetchPokemon(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	url, filename := pokemon.Cries.Latest, pokemon.Name+".ogg"
+	if legacy {
+		url, filename = pokemon.Cries.Legacy, pokemon.Name+"-legacy.ogg"
+	}
+	if url == "" {
+		return nil, fmt.Errorf("No cry available for %s", pokemon.Name)
+	}
+
+	data, err := api.Download(url, cfg.pokecache)
+	if err != nil {
+		return nil, fmt.Errorf("Error downloading cry: %w", err)
+	}
+
+	dir := cfg.cryDir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Error creating cry directory: %w", err)
+	}
+	path := filepath.Join(dir, filename)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("Error saving cry: %w", err)
+	}
+	saved := cryResult{
+		Pokemon: namedItem{Name: pokemon.Name, DisplayName: cfg.pokemonDisplayName(pokemon)},
+		Legacy:  legacy,
+		Path:    path,
+	}
+
+	if player := cfg.cryPlayer; player != "" {
+		return saved, playCry(player, path, cfg.out, cfg.errOut)
+	}
+	return saved, nil
+}
+
+// cryResult is where a downloaded cry was saved
+type cryResult struct {
+	Pokemon namedItem `json:"pokemon"`
+	Legacy  bool      `json:"legacy"`
+	Path    string    `json:"path"`
+}
+
+func (c cryResult) text(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Saved %s's cry to %s\n", c.Pokemon.DisplayName, c.Path)
+	return err
+}
+
+func (c cryResult) table() ([]string, [][]string) {
+	return []string{"pokemon", "legacy", "path"}, [][]string{{c.Pokemon.Name, strconv.FormatBool(c.Legacy), c.Path}}
+}
+
+// playCry runs the configured player command, sending its output to out and errOut. A "{}" in
+// the command is replaced with the file path, otherwise the path is appended as the last argument.
+func playCry(player, path string, out, errOut io.Writer) error {
+	args := strings.Fields(player)
+	replaced := false
+	for i, a := range args {
+		if strings.Contains(a, "{}") {
+			args[i] = strings.ReplaceAll(a, "{}", path)
+			replaced = true
+		}
+	}
+	if !replaced {
+		args = append(args, path)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Error playing cry with '%s': %w", args[0], err)
+	}
+	return nil
+}
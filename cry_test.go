@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandCry(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	dir := t.TempDir()
+	commands.config.cryDir = dir
+
+	// Use cp as the player so the hook leaves evidence behind
+	played := filepath.Join(dir, "played.ogg")
+	commands.config.cryPlayer = "cp {} " + played
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("cry magikarp") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "magikarp.ogg")
+	if !strings.Contains(out, "Saved Magikarp's cry to "+path) {
+		t.Errorf("unexpected cry output:\n%s", out)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected cry to be saved: %v", err)
+	}
+	if !strings.HasPrefix(string(saved), "OggS") || !strings.Contains(string(saved), "\xff\xfe") {
+		t.Errorf("saved cry is not the downloaded binary data: %q", saved)
+	}
+
+	copied, err := os.ReadFile(played)
+	if err != nil {
+		t.Fatalf("expected player hook to run: %v", err)
+	}
+	if string(copied) != string(saved) {
+		t.Errorf("player received a different file than the saved cry")
+	}
+}
+
+func TestCommandCryPlayerStderr(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	dir := t.TempDir()
+	commands.config.cryDir = dir
+
+	//ls fails on the missing file, and what it reports must reach the injected stderr
+	var stderr strings.Builder
+	commands.config.errOut = &stderr
+	commands.config.cryPlayer = "ls {}.missing"
+
+	var err error
+	captureOutput(commands, func() { err = commands.run("cry magikarp") })
+	if err == nil || !strings.Contains(err.Error(), "Error playing cry with 'ls'") {
+		t.Errorf("expected the player to fail, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "magikarp.ogg.missing") {
+		t.Errorf("expected the player's errors on the injected stderr, got %q", stderr.String())
+	}
+}
+
+func TestCommandCryLegacyMissing(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.cryDir = t.TempDir()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("cry gastrodon legacy") })
+	if err == nil || err.Error() != "No cry available for gastrodon" {
+		t.Errorf("expected a missing cry error, got %v", err)
+	}
+	if out != "" {
+		t.Errorf("unexpected cry output:\n%s", out)
+	}
+}
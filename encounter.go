@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"pokedexcli/internal/api"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// wildEncounter is the wild Pokemon the trainer is facing, the only one catch can target
+type wildEncounter struct {
+	Pokemon     string `json:"pokemon"`
+	DisplayName string `json:"display_name"`
+	Area        string `json:"area"`
+	Version     string `json:"version"`
+	Method      string `json:"method"`
+	MinLevel    int    `json:"min_level"`
+	MaxLevel    int    `json:"max_level"`
+	Level       int    `json:"level"`
+}
+
+// anyVersion is the version setting that allows encounters from every game version
+const anyVersion = "any"
+
+// versions are the game versions PokeAPI has encounters for
+var versions = []string{
+	"red", "blue", "yellow", "gold", "silver", "crystal", "ruby", "sapphire", "emerald", "firered", "leafgreen",
+	"diamond", "pearl", "platinum", "heartgold", "soulsilver", "black", "white", "black-2", "white-2",
+	"x", "y", "omega-ruby", "alpha-sapphire", "sun", "moon", "ultra-sun", "ultra-moon",
+	"lets-go-pikachu", "lets-go-eevee", "sword", "shield", "brilliant-diamond", "shining-pearl", "legends-arceus", "scarlet", "violet",
+}
+
+func validVersion(version string) bool {
+	return slices.Contains(versions, version)
+}
+
+// versionSetting is how the version setting is shown
+func (c *config) versionSetting() string {
+	if c.version == "" {
+		return anyVersion
+	}
+	return c.version
+}
+
+// fetchArea requests a location area by its resolved name
+func fetchArea(name string, cfg *config) (api.Area, error) {
+	var area api.Area
+	body, err := api.ApiRequest("https://pokeapi.co/api/v2/location-area/"+name, cfg.pokecache)
+	if err != nil {
+		if strings.Contains(err.Error(), "status code: 404") {
+			return area, notFoundError("Area", name, err)
+		}
+		return area, fmt.Errorf("Error exploring area: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &area); err != nil {
+		return area, fmt.Errorf("Error unmarshalling JSON: %w", err)
+	}
+	return area, nil
+}
+
+func commandTravel(cfg *config, a args) (result, error) {
+	arg, err := cfg.resolveArea(a.arg(0))
+	if err != nil {
+		return nil, err
+	}
+	area, err := fetchArea(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.seeAreas(area.Name)
+	cfg.location = area.Name
+	cfg.encounter = nil
+	return travelResult{Area: namedItem{Name: area.Name, DisplayName: cfg.areaDisplayName(area)}}, nil
+}
+
+// travelResult is the area the trainer arrived in
+type travelResult struct {
+	Area namedItem `json:"area"`
+}
+
+func (t travelResult) text(w io.Writer) error {
+	fmt.Fprintf(w, "You travelled to %s\n", t.Area.DisplayName)
+	fmt.Fprintln(w, "Use walk, fish, surf or headbutt to look for wild Pokemon")
+	return nil
+}
+
+func (t travelResult) table() ([]string, [][]string) {
+	return []string{"area", "display_name"}, [][]string{{t.Area.Name, t.Area.DisplayName}}
+}
+
+// encounterMethod is a way of looking for wild Pokemon, covering the PokeAPI encounter
+// methods it finds. When it uses their rates, a Pokemon only appears as often as the
+// area's rate for that method, otherwise one always appears.
+type encounterMethod struct {
+	methods []string
+	useRate bool
+	//none is shown when the area has no Pokemon for these methods, with the area name, and miss when the rate roll fails
+	none string
+	miss string
+}
+
+// walking finds Pokemon on foot, in grass, caves and the like
+var walking = encounterMethod{
+	methods: []string{"walk", "dark-grass", "grass-spots", "cave-spots", "bridge-spots", "rough-terrain", "yellow-flowers", "purple-flowers", "red-flowers"},
+	none:    "There are no wild Pokemon to find on foot in %s",
+}
+
+// rods are the fishing rods fish can use, keyed by the name given to fish
+var rods = map[string]encounterMethod{
+	"old": {
+		methods: []string{"old-rod"},
+		useRate: true,
+		none:    "There is nothing to fish with an old rod in %s",
+		miss:    "Not even a nibble...",
+	},
+	"good": {
+		methods: []string{"good-rod"},
+		useRate: true,
+		none:    "There is nothing to fish with a good rod in %s",
+		miss:    "Not even a nibble...",
+	},
+	"super": {
+		methods: []string{"super-rod", "super-rod-spots"},
+		useRate: true,
+		none:    "There is nothing to fish with a super rod in %s",
+		miss:    "Not even a nibble...",
+	},
+}
+
+var surfing = encounterMethod{
+	methods: []string{"surf", "surf-spots"},
+	useRate: true,
+	none:    "There are no wild Pokemon in the water of %s",
+	miss:    "You surfed around, but no wild Pokemon appeared",
+}
+
+var headbutting = encounterMethod{
+	methods: []string{"headbutt", "headbutt-low", "headbutt-normal", "headbutt-high"},
+	useRate: true,
+	none:    "There are no trees with wild Pokemon in %s",
+	miss:    "You headbutted a tree, but nothing fell out",
+}
+
+func commandWalk(cfg *config, _ args) (result, error) {
+	return cfg.encounterBy(walking)
+}
+
+func commandFish(cfg *config, a args) (result, error) {
+	rod := strings.ToLower(a.arg(0))
+	if rod == "" {
+		rod = "old"
+	}
+	method, exists := rods[rod]
+	if !exists {
+		return nil, fmt.Errorf("Unknown rod '%s'. Usage: fish [old|good|super]", rod)
+	}
+	return cfg.encounterBy(method)
+}
+
+func commandSurf(cfg *config, _ args) (result, error) {
+	return cfg.encounterBy(surfing)
+}
+
+func commandHeadbutt(cfg *config, _ args) (result, error) {
+	return cfg.encounterBy(headbutting)
+}
+
+// encounterBy looks for a wild Pokemon at the current location using one method.
+// Whatever was encountered before runs off, whether or not a new Pokemon appears.
+func (c *config) encounterBy(method encounterMethod) (result, error) {
+	if c.location == "" {
+		return nil, errors.New("You are not anywhere yet. Use travel <area> to go somewhere")
+	}
+	area, err := fetchArea(c.location, c)
+	if err != nil {
+		return nil, err
+	}
+	c.encounter = nil
+
+	rows := []encounterRow{}
+	for _, row := range aggregateEncounters(area, c.version, "") {
+		if slices.Contains(method.methods, row.Method) {
+			rows = append(rows, row)
+		}
+	}
+	row, found := rollEncounter(c.rng, rows)
+	if !found {
+		message := fmt.Sprintf(method.none, c.areaDisplayName(area))
+		if c.version != "" {
+			message += " in " + c.version
+		}
+		return noEncounter{Message: message}, nil
+	}
+	if method.useRate && c.rng.Intn(100) >= methodRate(area, row.Method, row.Version) {
+		return noEncounter{Message: method.miss}, nil
+	}
+
+	encounter := wildEncounter{
+		Pokemon:     row.Pokemon,
+		DisplayName: row.Pokemon,
+		Area:        area.Name,
+		Version:     row.Version,
+		Method:      row.Method,
+		MinLevel:    row.MinLevel,
+		MaxLevel:    row.MaxLevel,
+		Level:       row.MinLevel,
+	}
+	if row.MaxLevel > row.MinLevel {
+		encounter.Level += c.rng.Intn(row.MaxLevel - row.MinLevel + 1)
+	}
+	//The name shown is the species name, falling back to the slug if the Pokemon cannot be fetched
+	for _, pokemon := range area.PokemonEncounters {
+		if pokemon.Pokemon.Name != row.Pokemon {
+			continue
+		}
+		var mon api.Pokemon
+		if body, err := api.ApiRequest(pokemon.Pokemon.URL, c.pokecache); err == nil && json.Unmarshal(body, &mon) == nil {
+			encounter.DisplayName = c.pokemonDisplayName(mon)
+		}
+		break
+	}
+	c.encounter = &encounter
+	return encounter, nil
+}
+
+// methodRate is the percent chance of meeting a Pokemon each time a method is
+// used in a version. Methods without a rate always find one.
+func methodRate(area api.Area, method, version string) int {
+	for _, rate := range area.EncounterMethodRates {
+		if rate.EncounterMethod.Name != method {
+			continue
+		}
+		for _, vd := range rate.VersionDetails {
+			if vd.Version.Name == version {
+				return vd.Rate
+			}
+		}
+	}
+	return 100
+}
+
+// noEncounter is the outcome of looking for a wild Pokemon without finding one
+type noEncounter struct {
+	Message string `json:"message"`
+}
+
+func (n noEncounter) text(w io.Writer) error {
+	_, err := fmt.Fprintln(w, n.Message)
+	return err
+}
+
+func (n noEncounter) table() ([]string, [][]string) {
+	return []string{"message"}, [][]string{{n.Message}}
+}
+
+// rollEncounter picks one encounter, each weighted by its chance
+func rollEncounter(rng *rand.Rand, rows []encounterRow) (encounterRow, bool) {
+	total := 0
+	for _, row := range rows {
+		total += row.Chance
+	}
+	if total <= 0 {
+		return encounterRow{}, false
+	}
+
+	roll := rng.Intn(total)
+	for _, row := range rows {
+		if roll < row.Chance {
+			return row, true
+		}
+		roll -= row.Chance
+	}
+	return encounterRow{}, false
+}
+
+func (e wildEncounter) text(w io.Writer) error {
+	fmt.Fprintf(w, "A wild %s (level %d) appeared!\n", e.DisplayName, e.Level)
+	fmt.Fprintf(w, "Try to catch it with catch %s\n", e.Pokemon)
+	return nil
+}
+
+func (e wildEncounter) table() ([]string, [][]string) {
+	return []string{"pokemon", "display_name", "area", "version", "method", "min_level", "max_level", "level"},
+		[][]string{{e.Pokemon, e.DisplayName, e.Area, e.Version, e.Method, strconv.Itoa(e.MinLevel), strconv.Itoa(e.MaxLevel), strconv.Itoa(e.Level)}}
+}
+
+// checkEncounter makes sure the Pokemon being caught is the one encountered
+func (c *config) checkEncounter(name string) error {
+	if c.encounter == nil {
+		return errors.New("There is no wild Pokemon here to catch. Use walk, fish, surf or headbutt to look for one")
+	}
+	if c.encounter.Pokemon != name {
+		return fmt.Errorf("There is no wild %s here, only %s", name, c.encounter.DisplayName)
+	}
+	return nil
+}
+
+func completeFish(_ *config, prev []string) []string {
+	if len(prev) != 0 {
+		return nil
+	}
+	return []string{"old", "good", "super"}
+}
+
+func completeEncounter(cfg *config, prev []string) []string {
+	if len(prev) != 0 || cfg.encounter == nil {
+		return nil
+	}
+	return []string{cfg.encounter.Pokemon}
+}
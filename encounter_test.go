@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRollEncounter(t *testing.T) {
+	rows := []encounterRow{
+		{Pokemon: "magikarp", Chance: 90},
+		{Pokemon: "gyarados", Chance: 10},
+		{Pokemon: "feebas", Chance: 0},
+	}
+	rng := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for range 1000 {
+		row, found := rollEncounter(rng, rows)
+		if !found {
+			t.Fatal("expected an encounter")
+		}
+		counts[row.Pokemon]++
+	}
+	if counts["feebas"] != 0 || counts["magikarp"] < 850 || counts["gyarados"] < 50 {
+		t.Errorf("expected encounters weighted by chance, got %v", counts)
+	}
+
+	if _, found := rollEncounter(rng, nil); found {
+		t.Error("expected no encounter without any encounter slots")
+	}
+}
+
+func TestTravelAndWalk(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.rng = rand.New(rand.NewSource(4))
+
+	if err := commands.run("walk"); err == nil || !strings.Contains(err.Error(), "Use travel <area>") {
+		t.Errorf("expected walking nowhere to fail, got %v", err)
+	}
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"travel pastoria-city", "encounter", "fish super"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: unexpected error: %v", line, err)
+			}
+		}
+	})
+	if cfg.location != "pastoria-city-area" || cfg.encounter == nil {
+		t.Fatalf("expected an encounter in pastoria-city-area, got %q and %v", cfg.location, cfg.encounter)
+	}
+	expected := []string{
+		"You travelled to Pastoria City",
+		"Use walk, fish, surf or headbutt to look for wild Pokemon",
+		"There are no wild Pokemon to find on foot in Pastoria City",
+		fmt.Sprintf("A wild %s (level %d) appeared!", cfg.encounter.DisplayName, cfg.encounter.Level),
+	}
+	if !strings.Contains(out, strings.Join(expected, "\n")) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	//Only the encountered Pokemon can be caught, and travelling leaves it behind
+	cfg.encounter = &wildEncounter{Pokemon: "magikarp", DisplayName: "Magikarp"}
+	if err := commands.run("catch gastrodon"); err == nil || !strings.Contains(err.Error(), "only Magikarp") {
+		t.Errorf("expected catching another Pokemon to fail, got %v", err)
+	}
+
+	//A form is checked against the encountered variety
+	cfg.encounter = &wildEncounter{Pokemon: "charizard", DisplayName: "Charizard"}
+	if err := commands.run("catch charizard --form mega-x"); err == nil || !strings.Contains(err.Error(), "no wild charizard-mega-x here, only Charizard") {
+		t.Errorf("expected catching another form to fail, got %v", err)
+	}
+	cfg.encounter = &wildEncounter{Pokemon: "charizard-mega-x", DisplayName: "Mega Charizard X", Level: 50}
+	captureOutput(commands, func() {
+		if err := commands.run("catch charizard --form mega-x --ball master"); err != nil {
+			t.Errorf("unexpected error catching the encountered form: %v", err)
+		}
+	})
+	if len(cfg.caught) != 1 || cfg.caught[0].Species != "charizard-mega-x" || cfg.encounter != nil {
+		t.Errorf("expected the encountered form to be caught, got %v", cfg.caught)
+	}
+	captureOutput(commands, func() { commands.run("travel pastoria-city-area") })
+	if cfg.encounter != nil {
+		t.Errorf("expected the encounter to end when travelling")
+	}
+}
+
+func TestVersionSetting(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.rng = rand.New(rand.NewSource(1))
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"set version pearl", "explore pastoria-city-area --table --method super-rod", "travel pastoria-city-area"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: unexpected error: %v", line, err)
+			}
+		}
+	})
+	if !strings.Contains(out, "gyarados  pearl    super-rod  30-55   40%") || strings.Contains(out, "platinum") {
+		t.Errorf("expected only pearl encounters, got %q", out)
+	}
+
+	//Wild Pokemon are met in the selected version at a level within the range of their encounter
+	for range 50 {
+		res, err := commandFish(cfg, args{positional: []string{"good"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		encounter, found := res.(wildEncounter)
+		if !found {
+			continue
+		}
+		if encounter.Version != "pearl" || encounter.Level < encounter.MinLevel || encounter.Level > encounter.MaxLevel {
+			t.Fatalf("expected a pearl encounter within its level range, got %+v", encounter)
+		}
+	}
+
+	if err := commands.run("set version mars"); err == nil || !strings.Contains(err.Error(), "Unknown version 'mars'") {
+		t.Errorf("expected an unknown version error, got %v", err)
+	}
+	captureOutput(commands, func() { commands.run("set version any") })
+	if cfg.version != "" || cfg.versionSetting() != anyVersion {
+		t.Errorf("expected any version to clear the setting, got %q", cfg.version)
+	}
+}
+
+func TestEncounterMethods(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.rng = rand.New(rand.NewSource(1))
+	captureOutput(commands, func() { commands.run("travel pastoria-city-area") })
+
+	//Each method only finds the Pokemon encountered that way, as often as the area's rate for it
+	cases := []struct {
+		method  func(*config, args) (result, error)
+		args    []string
+		pokemon []string
+		rate    int
+	}{
+		{commandFish, nil, []string{"magikarp"}, 25},
+		{commandFish, []string{"good"}, []string{"magikarp", "gyarados"}, 50},
+		{commandFish, []string{"super"}, []string{"gyarados"}, 75},
+		{commandSurf, nil, []string{"tentacool", "tentacruel", "shellos", "gastrodon"}, 10},
+	}
+	for _, c := range cases {
+		found := 0
+		for range 1000 {
+			res, err := c.method(cfg, args{positional: c.args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			encounter, ok := res.(wildEncounter)
+			if !ok {
+				continue
+			}
+			found++
+			if !slices.Contains(c.pokemon, encounter.Pokemon) {
+				t.Errorf("%v: unexpected encounter with %s by %s", c.args, encounter.Pokemon, encounter.Method)
+			}
+		}
+		if found < c.rate*10-60 || found > c.rate*10+60 {
+			t.Errorf("%v: expected about %d encounters in 1000 tries, got %d", c.args, c.rate*10, found)
+		}
+	}
+
+	res, err := commandHeadbutt(cfg, args{})
+	if err != nil || res.(noEncounter).Message != "There are no trees with wild Pokemon in Pastoria City" {
+		t.Errorf("expected no trees in Pastoria City, got %v (%v)", res, err)
+	}
+	if _, err := commandFish(cfg, args{positional: []string{"mega"}}); err == nil || !strings.Contains(err.Error(), "Unknown rod 'mega'") {
+		t.Errorf("expected an unknown rod error, got %v", err)
+	}
+}
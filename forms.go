@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pokedexcli/internal/api"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fetchSpecies looks up a species by name, falling back to the species of a
+// matching Pokemon so that variety names and dex numbers work too
+func fetchSpecies(query string, cfg *config) (api.PokemonSpecies, error) {
+	query = strings.ToLower(query)
+	var species api.PokemonSpecies
+	body, err := api.ApiRequest("https://pokeapi.co/api/v2/pokemon-species/"+query, cfg.pokecache)
+	if err != nil {
+		if !strings.Contains(err.Error(), "status code: 404") {
+			return species, fmt.Errorf("Error looking up species %s: %w", query, err)
+		}
+
+		name, err := cfg.resolvePokemon(query)
+		if err != nil {
+			return species, err
+		}
+		pokemon, err := fetchPokemon(name, cfg)
+		if err != nil {
+			return species, err
+		}
+		body, err = api.ApiRequest(pokemon.Species.URL, cfg.pokecache)
+		if err != nil {
+			return species, fmt.Errorf("Error looking up species of %s: %w", name, err)
+		}
+	}
+
+	if err := json.Unmarshal(body, &species); err != nil {
+		return species, fmt.Errorf("Error unmarshalling JSON: %w", err)
+	}
+	return species, nil
+}
+
+// resolveVariety finds the Pokemon for a form of a species, accepting either
+// the short form name ("mega-x") or the full name ("charizard-mega-x")
+func resolveVariety(query, form string, cfg *config) (string, error) {
+	species, err := fetchSpecies(query, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	available := []string{}
+	for _, variety := range species.Varieties {
+		name := variety.Pokemon.Name
+		form = strings.ToLower(form)
+		if name == form || name == species.Name+"-"+form {
+			return name, nil
+		}
+		if !variety.IsDefault {
+			available = append(available, strings.TrimPrefix(name, species.Name+"-"))
+		}
+	}
+
+	if len(available) == 0 {
+		return "", fmt.Errorf("%s has no alternate forms", species.Name)
+	}
+	return "", fmt.Errorf("%s has no form '%s'. Available forms: %s", species.Name, form, strings.Join(available, ", "))
+}
+
+func commandForms(cfg *config, a args) (result, error) {
+	arg := a.arg(0)
+	species, err := fetchSpecies(arg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, len(species.Varieties))
+	for i, variety := range species.Varieties {
+		urls[i] = variety.Pokemon.URL
+	}
+	varieties, fetchErr := api.FetchMany(urls, cfg.pokecache, cfg.concurrency)
+
+	//Look up every form of every variety in one batch
+	pokemon := make([]api.Pokemon, len(varieties))
+	formURLs := []string{}
+	for i, fetched := range varieties {
+		if fetched.Err != nil || json.Unmarshal(fetched.Body, &pokemon[i]) != nil {
+			continue
+		}
+		for _, form := range pokemon[i].Forms {
+			formURLs = append(formURLs, form.URL)
+		}
+	}
+	formResults, formErr := api.FetchMany(formURLs, cfg.pokecache, cfg.concurrency)
+	forms := map[string]api.PokemonForm{}
+	for _, fetched := range formResults {
+		var form api.PokemonForm
+		if fetched.Err == nil && json.Unmarshal(fetched.Body, &form) == nil {
+			forms[fetched.URL] = form
+		}
+	}
+
+	list := formList{Species: namedItem{Name: species.Name, DisplayName: species.Name}}
+	if name, ok := api.LocalizedName(species.Names, cfg.language); ok {
+		list.Species.DisplayName = name
+	}
+	for i, variety := range species.Varieties {
+		entry := varietyEntry{
+			Name:        variety.Pokemon.Name,
+			DisplayName: speciesDisplayName(variety.Pokemon.Name, species, cfg.language),
+			Default:     variety.IsDefault,
+			Forms:       []namedItem{},
+		}
+		for j, ref := range pokemon[i].Forms {
+			form, exists := forms[ref.URL]
+			if !exists {
+				continue
+			}
+			entry.Mega = entry.Mega || form.IsMega
+			entry.BattleOnly = entry.BattleOnly || form.IsBattleOnly
+			display, ok := api.LocalizedName(form.Names, cfg.language)
+			if !ok {
+				display = form.Name
+			}
+			//The first form of a variety names it when PokeAPI has a name for it, like Mega Charizard X
+			if j == 0 && ok {
+				entry.DisplayName = display
+			}
+			entry.Forms = append(entry.Forms, namedItem{Name: form.Name, DisplayName: display})
+		}
+		list.Varieties = append(list.Varieties, entry)
+	}
+
+	if fetchErr != nil {
+		return list, fmt.Errorf("Error fetching varieties: %w", fetchErr)
+	}
+	if formErr != nil {
+		return list, fmt.Errorf("Error fetching forms: %w", formErr)
+	}
+	return list, nil
+}
+
+// formList is every variety of a species and the cosmetic forms of each
+type formList struct {
+	Species   namedItem      `json:"species"`
+	Varieties []varietyEntry `json:"varieties"`
+}
+
+type varietyEntry struct {
+	Name        string      `json:"name"`
+	DisplayName string      `json:"display_name"`
+	Default     bool        `json:"default"`
+	Mega        bool        `json:"mega"`
+	BattleOnly  bool        `json:"battle_only"`
+	Forms       []namedItem `json:"forms"`
+}
+
+func (l formList) text(w io.Writer) error {
+	fmt.Fprintf(w, "Forms of %s:\n", l.Species.DisplayName)
+	for _, variety := range l.Varieties {
+		tags := []string{}
+		if variety.Default {
+			tags = append(tags, "default")
+		}
+		if variety.Mega {
+			tags = append(tags, "mega")
+		}
+		if variety.BattleOnly {
+			tags = append(tags, "battle only")
+		}
+
+		line := " - " + variety.DisplayName
+		if len(tags) > 0 {
+			line += " [" + strings.Join(tags, ", ") + "]"
+		}
+		fmt.Fprintln(w, line)
+
+		//Cosmetic forms share a single Pokemon, so list them underneath it
+		if len(variety.Forms) > 1 {
+			names := make([]string, len(variety.Forms))
+			for i, form := range variety.Forms {
+				names[i] = form.DisplayName
+			}
+			fmt.Fprintf(w, "     forms: %s\n", strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+func (l formList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Varieties))
+	for i, v := range l.Varieties {
+		forms := make([]string, len(v.Forms))
+		for j, form := range v.Forms {
+			forms[j] = form.Name
+		}
+		rows[i] = []string{l.Species.Name, v.Name, v.DisplayName, strconv.FormatBool(v.Default), strconv.FormatBool(v.Mega),
+			strconv.FormatBool(v.BattleOnly), strings.Join(forms, " ")}
+	}
+	return []string{"species", "variety", "display_name", "default", "mega", "battle_only", "forms"}, rows
+}
+
+// groupBySpecies returns the caught Pokemon names grouped under their species, both sorted
+func groupBySpecies(pokedex map[string]dexPokemon) ([]string, map[string][]string) {
+	groups := map[string][]string{}
+	for name, pokemon := range pokedex {
+		species := pokemon.Species.Name
+		if species == "" {
+			species = name
+		}
+		groups[species] = append(groups[species], name)
+	}
+
+	species := make([]string, 0, len(groups))
+	for s := range groups {
+		species = append(species, s)
+		sort.Strings(groups[s])
+	}
+	sort.Strings(species)
+	return species, groups
+}
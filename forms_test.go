@@ -0,0 +1,113 @@
+package main
+
+import (
+	"pokedexcli/internal/api"
+	"slices"
+	"testing"
+)
+
+func TestCommandForms(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"forms charizard", "forms shellos"} {
+			if err = commands.run(line); err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Forms of Charizard:\n" +
+		" - Charizard [default]\n" +
+		" - Charizard (mega-x) [mega, battle only]\n" +
+		" - Charizard (mega-y) [mega, battle only]\n" +
+		"Forms of Shellos:\n" +
+		" - Shellos [default]\n" +
+		"     forms: shellos-west, shellos-east\n"
+	if out != expected {
+		t.Errorf("unexpected forms output:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestResolveVariety(t *testing.T) {
+	useCassette(t, "commands")
+	cfg := createRegistry().config
+
+	cases := []struct {
+		species  string
+		form     string
+		expected string
+	}{
+		{"charizard", "mega-x", "charizard-mega-x"},
+		{"charizard", "MEGA-Y", "charizard-mega-y"},
+		{"charizard", "charizard-mega-x", "charizard-mega-x"},
+		{"charizard", "charizard", "charizard"},
+	}
+	for _, c := range cases {
+		name, err := resolveVariety(c.species, c.form, cfg)
+		if err != nil {
+			t.Errorf("%s --form %s: unexpected error: %v", c.species, c.form, err)
+			continue
+		}
+		if name != c.expected {
+			t.Errorf("%s --form %s: expected %s, got %s", c.species, c.form, c.expected, name)
+		}
+	}
+
+	//Unknown forms list the ones that exist
+	errorCases := map[string]string{
+		"charizard": "charizard has no form 'mega-z'. Available forms: mega-x, mega-y",
+		"shellos":   "shellos has no alternate forms",
+	}
+	for species, expected := range errorCases {
+		if _, err := resolveVariety(species, "mega-z", cfg); err == nil || err.Error() != expected {
+			t.Errorf("%s --form mega-z: expected %q, got %v", species, expected, err)
+		}
+	}
+}
+
+func TestPokedexGroupsForms(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+
+	charizard := api.NamedResource{Name: "charizard", URL: "https://pokeapi.co/api/v2/pokemon-species/6/"}
+	cfg.pokedex = map[string]dexPokemon{
+		"charizard-mega-x": {Name: "charizard-mega-x", Species: charizard},
+		"charizard":        {Name: "charizard", Species: charizard},
+		"magikarp":         {Name: "magikarp", Species: api.NamedResource{Name: "magikarp", URL: "https://pokeapi.co/api/v2/pokemon-species/129/"}},
+	}
+	cfg.caught = []caughtPokemon{
+		{ID: 1, Species: "charizard-mega-x", Level: 50},
+		{ID: 2, Species: "magikarp"},
+		{ID: 3, Species: "charizard", Nickname: "Blaze"},
+	}
+
+	res, err := commandPokedex(cfg, args{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dex := res.(pokedexResult)
+	if len(dex.Species) != 2 || !slices.Equal(dex.Species[0].Pokemon, []string{"charizard", "charizard-mega-x"}) {
+		t.Fatalf("expected both charizard forms under one species, got %+v", dex.Species)
+	}
+
+	out := captureOutput(commands, func() { err = commands.run("pokedex") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Your Pokedex:\n" +
+		" - charizard\n" +
+		"     #1 (charizard-mega-x), level 50\n" +
+		"     #3 Blaze\n" +
+		" - magikarp\n" +
+		"     #2\n"
+	if out != expected {
+		t.Errorf("unexpected pokedex output:\n%s\nexpected:\n%s", out, expected)
+	}
+}
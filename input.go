@@ -1,14 +1,139 @@
 package main
 
 import (
+	"errors"
 	"strings"
 )
 
-func cleanInput(text string) []string {
-	text = strings.ToLower(text)
-	words := strings.Fields(text)
-	//cleanWords := []string
-	//for _, word := range words {
-	//}
-	return words
+// args holds the parsed arguments of a command line
+type args struct {
+	positional []string
+	flags      map[string]string
+	//input holds the items piped into a stream command, nil when it is not piped
+	input []item
+}
+
+// arg returns the positional argument at index i, or "" if there is none
+func (a args) arg(i int) string {
+	if i < 0 || i >= len(a.positional) {
+		return ""
+	}
+	return a.positional[i]
+}
+
+// flag returns the value of --name and whether it was given at all
+func (a args) flag(name string) (string, bool) {
+	val, exists := a.flags[name]
+	return val, exists
+}
+
+// has reports whether --name was given
+func (a args) has(name string) bool {
+	_, exists := a.flags[name]
+	return exists
+}
+
+// tokenize splits a line into words on whitespace. Single or double quotes group
+// words together and a backslash escapes the next character outside single quotes.
+func tokenize(line string) ([]string, error) {
+	stages, err := split(line, false)
+	if err != nil {
+		return nil, err
+	}
+	return stages[0], nil
+}
+
+// tokenizePipeline splits a line into the words of each command in a pipeline.
+// An unquoted, unescaped | separates commands.
+func tokenizePipeline(line string) ([][]string, error) {
+	return split(line, true)
+}
+
+func split(line string, pipes bool) ([][]string, error) {
+	stages := [][]string{}
+	tokens := []string{}
+	var current strings.Builder
+	inToken := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r' || (pipes && r == '|'):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+			if r == '|' {
+				stages = append(stages, tokens)
+				tokens = []string{}
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return append(stages, tokens), nil
+}
+
+// parseArgs separates --flag value, --flag=value and positional arguments. Flags
+// listed in boolFlags never take a value, and "--" ends flag parsing.
+func parseArgs(tokens []string, boolFlags []string) args {
+	a := args{positional: []string{}, flags: map[string]string{}}
+	isBool := map[string]bool{}
+	for _, f := range boolFlags {
+		isBool[f] = true
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "--" {
+			a.positional = append(a.positional, tokens[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(token, "--") || len(token) == 2 {
+			a.positional = append(a.positional, token)
+			continue
+		}
+
+		name := strings.TrimPrefix(token, "--")
+		if key, val, found := strings.Cut(name, "="); found {
+			a.flags[strings.ToLower(key)] = val
+			continue
+		}
+		name = strings.ToLower(name)
+		if !isBool[name] && i+1 < len(tokens) && !strings.HasPrefix(tokens[i+1], "--") {
+			a.flags[name] = tokens[i+1]
+			i++
+			continue
+		}
+		a.flags[name] = ""
+	}
+	return a
 }
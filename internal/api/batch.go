@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"pokedexcli/internal/pokecache"
+	"sync"
+)
+
+// DefaultConcurrency is the number of workers FetchMany uses when none is given
+const DefaultConcurrency = 8
+
+// FetchResult holds the outcome of fetching a single URL in a batch
+type FetchResult struct {
+	URL  string
+	Body []byte
+	Err  error
+}
+
+// FetchMany requests every URL through the shared cache using a bounded pool of
+// workers. Results are returned in the same order as urls, and any per-item
+// failures are joined into the returned error.
+func FetchMany(urls []string, cache *pokecache.Cache, concurrency int) ([]FetchResult, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]FetchResult, len(urls))
+
+	// Only fetch each distinct URL once, the duplicates are filled in afterwards
+	first := make(map[string]int, len(urls))
+	jobs := make(chan int)
+	for i, url := range urls {
+		results[i].URL = url
+		if _, seen := first[url]; !seen {
+			first[url] = i
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(first)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Body, results[i].Err = ApiRequest(results[i].URL, cache)
+			}
+		}()
+	}
+
+	for i, url := range urls {
+		if first[url] == i {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for i, url := range urls {
+		if j := first[url]; j != i {
+			results[i].Body, results[i].Err = results[j].Body, results[j].Err
+		}
+		if results[i].Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, results[i].Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pokedexcli/internal/pokecache"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchManyOrderAndErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Make earlier requests finish later to prove ordering is preserved
+		if r.URL.Path == "/0" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Write([]byte("body" + r.URL.Path))
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/0", server.URL + "/missing", server.URL + "/2", server.URL + "/3"}
+	results, err := FetchMany(urls, pokecache.NewCache(5*time.Minute), 4)
+
+	if err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Errorf("expected aggregated error naming the failed URL, got: %v", err)
+	}
+
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+
+	for i, want := range []string{"body/0", "", "body/2", "body/3"} {
+		if results[i].URL != urls[i] {
+			t.Errorf("result %d: expected URL %s, got %s", i, urls[i], results[i].URL)
+		}
+		if string(results[i].Body) != want {
+			t.Errorf("result %d: expected body '%s', got '%s'", i, want, string(results[i].Body))
+		}
+	}
+
+	if results[1].Err == nil {
+		t.Errorf("expected per-item error for missing URL")
+	}
+}
+
+func TestFetchManyBoundedConcurrency(t *testing.T) {
+	const concurrency = 3
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		mu.Lock()
+		if n > maxInFlight {
+			maxInFlight = n
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	urls := make([]string, 20)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/%d", server.URL, i)
+	}
+
+	if _, err := FetchMany(urls, pokecache.NewCache(5*time.Minute), concurrency); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if maxInFlight > concurrency {
+		t.Errorf("expected at most %d requests in flight, saw %d", concurrency, maxInFlight)
+	}
+}
+
+func TestFetchManySharesCache(t *testing.T) {
+	serverCallCount := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&serverCallCount, 1)
+		w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(5 * time.Minute)
+	cache.Add(server.URL+"/cached", []byte("cached"))
+
+	urls := []string{server.URL + "/cached", server.URL + "/new", server.URL + "/new"}
+	results, err := FetchMany(urls, cache, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(results[0].Body) != "cached" {
+		t.Errorf("expected cached body, got '%s'", string(results[0].Body))
+	}
+	if string(results[2].Body) != "fresh" {
+		t.Errorf("expected duplicate URL to share the result, got '%s'", string(results[2].Body))
+	}
+	if serverCallCount != 1 {
+		t.Errorf("server was called %d times, expected 1", serverCallCount)
+	}
+	if _, exists := cache.Get(server.URL + "/new"); !exists {
+		t.Errorf("expected fetched data to be cached")
+	}
+}
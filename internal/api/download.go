@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+	"pokedexcli/internal/pokecache"
+)
+
+// Download fetches a binary file such as a sprite or cry through the cache. The
+// returned slice is a copy, so callers may modify it without touching the cache.
+func Download(url string, cache *pokecache.Cache) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("Error downloading file: no URL")
+	}
+
+	body, err := ApiRequest(url, cache)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("Error downloading file: %s returned no data", url)
+	}
+
+	data := make([]byte, len(body))
+	copy(data, body)
+	return data, nil
+}
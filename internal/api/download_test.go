@@ -0,0 +1,48 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"pokedexcli/internal/pokecache"
+	"testing"
+	"time"
+)
+
+func TestDownloadBinary(t *testing.T) {
+	// OggS header followed by bytes that are not valid UTF-8
+	ogg := []byte{'O', 'g', 'g', 'S', 0x00, 0x02, 0xff, 0xfe, 0x00, 0x80}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/ogg")
+		w.Write(ogg)
+	}))
+	defer server.Close()
+
+	cache := pokecache.NewCache(5 * time.Minute)
+	data, err := Download(server.URL, cache)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, ogg) {
+		t.Errorf("downloaded bytes do not match, got %v", data)
+	}
+
+	// Modifying the result must not corrupt the cached copy
+	data[0] = 'X'
+	cached, _ := cache.Get(server.URL)
+	if !bytes.Equal(cached, ogg) {
+		t.Errorf("cached bytes were modified through the returned slice")
+	}
+}
+
+func TestDownloadEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := Download(server.URL, pokecache.NewCache(5*time.Minute)); err == nil {
+		t.Errorf("expected error for empty download")
+	}
+	if _, err := Download("", pokecache.NewCache(5*time.Minute)); err == nil {
+		t.Errorf("expected error for missing URL")
+	}
+}
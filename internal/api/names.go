@@ -0,0 +1,37 @@
+package api
+
+import "strings"
+
+// Name is a resource name in a single language
+type Name struct {
+	Language NamedResource `json:"language"`
+	Name     string        `json:"name"`
+}
+
+// FlavorText is a Pokedex entry from one game in a single language
+type FlavorText struct {
+	FlavorText string        `json:"flavor_text"`
+	Language   NamedResource `json:"language"`
+	Version    NamedResource `json:"version"`
+}
+
+// LocalizedName returns the name in the given language, if there is one
+func LocalizedName(names []Name, language string) (string, bool) {
+	for _, n := range names {
+		if n.Language.Name == language && n.Name != "" {
+			return n.Name, true
+		}
+	}
+	return "", false
+}
+
+// LocalizedFlavorText returns the most recent flavor text in the given language,
+// with the line and page breaks from the games collapsed into spaces
+func LocalizedFlavorText(entries []FlavorText, language string) (string, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Language.Name == language {
+			return strings.Join(strings.Fields(entries[i].FlavorText), " "), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,37 @@
+package api
+
+import "testing"
+
+func TestLocalizedName(t *testing.T) {
+	names := []Name{
+		{Language: NamedResource{Name: "fr"}, Name: "Voilaroc"},
+		{Language: NamedResource{Name: "en"}, Name: "Pastoria City"},
+		{Language: NamedResource{Name: "de"}, Name: ""},
+	}
+
+	if name, ok := LocalizedName(names, "fr"); !ok || name != "Voilaroc" {
+		t.Errorf("expected Voilaroc, got %s", name)
+	}
+	if _, ok := LocalizedName(names, "de"); ok {
+		t.Errorf("expected empty name to count as missing")
+	}
+	if _, ok := LocalizedName(names, "ja"); ok {
+		t.Errorf("expected missing language to not be found")
+	}
+}
+
+func TestLocalizedFlavorText(t *testing.T) {
+	entries := []FlavorText{
+		{FlavorText: "Old\nentry", Language: NamedResource{Name: "en"}},
+		{FlavorText: "Entrée", Language: NamedResource{Name: "fr"}},
+		{FlavorText: "Newer\fentry\nwith breaks", Language: NamedResource{Name: "en"}},
+	}
+
+	text, ok := LocalizedFlavorText(entries, "en")
+	if !ok || text != "Newer entry with breaks" {
+		t.Errorf("expected latest cleaned entry, got '%s'", text)
+	}
+	if _, ok := LocalizedFlavorText(entries, "ko"); ok {
+		t.Errorf("expected missing language to not be found")
+	}
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Pokemon holds the fields the CLI reads on every catch. The sprite set, move
+// list and game indices are large and rarely needed, so they are kept as raw
+// JSON and only decoded on request through the Decode methods.
+type Pokemon struct {
+	Abilities []struct {
+		Ability struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"ability"`
+		IsHidden bool `json:"is_hidden"`
+		Slot     int  `json:"slot"`
+	} `json:"abilities"`
+	BaseExperience int `json:"base_experience"`
+	Cries          struct {
+		Latest string `json:"latest"`
+		Legacy string `json:"legacy"`
+	} `json:"cries"`
+	Forms []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"forms"`
+	GameIndices            json.RawMessage `json:"game_indices"`
+	Height                 int             `json:"height"`
+	HeldItems              []any           `json:"held_items"`
+	ID                     int             `json:"id"`
+	IsDefault              bool            `json:"is_default"`
+	LocationAreaEncounters string          `json:"location_area_encounters"`
+	Moves                  json.RawMessage `json:"moves"`
+	Name                   string          `json:"name"`
+	Order                  int             `json:"order"`
+	PastAbilities          []struct {
+		Abilities []struct {
+			Ability  any  `json:"ability"`
+			IsHidden bool `json:"is_hidden"`
+			Slot     int  `json:"slot"`
+		} `json:"abilities"`
+		Generation struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"generation"`
+	} `json:"past_abilities"`
+	PastTypes []any `json:"past_types"`
+	Species   struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+	Sprites json.RawMessage `json:"sprites"`
+	Stats   []struct {
+		BaseStat int `json:"base_stat"`
+		Effort   int `json:"effort"`
+		Stat     struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"stat"`
+	} `json:"stats"`
+	Types []struct {
+		Slot int `json:"slot"`
+		Type struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"type"`
+	} `json:"types"`
+	Weight int `json:"weight"`
+}
+
+type PokemonSprites struct {
+	BackDefault      string `json:"back_default"`
+	BackFemale       string `json:"back_female"`
+	BackShiny        string `json:"back_shiny"`
+	BackShinyFemale  string `json:"back_shiny_female"`
+	FrontDefault     string `json:"front_default"`
+	FrontFemale      string `json:"front_female"`
+	FrontShiny       string `json:"front_shiny"`
+	FrontShinyFemale string `json:"front_shiny_female"`
+	Other            struct {
+		DreamWorld struct {
+			FrontDefault string `json:"front_default"`
+			FrontFemale  any    `json:"front_female"`
+		} `json:"dream_world"`
+		Home struct {
+			FrontDefault     string `json:"front_default"`
+			FrontFemale      string `json:"front_female"`
+			FrontShiny       string `json:"front_shiny"`
+			FrontShinyFemale string `json:"front_shiny_female"`
+		} `json:"home"`
+		OfficialArtwork struct {
+			FrontDefault string `json:"front_default"`
+			FrontShiny   string `json:"front_shiny"`
+		} `json:"official-artwork"`
+		Showdown struct {
+			BackDefault      string `json:"back_default"`
+			BackFemale       string `json:"back_female"`
+			BackShiny        string `json:"back_shiny"`
+			BackShinyFemale  any    `json:"back_shiny_female"`
+			FrontDefault     string `json:"front_default"`
+			FrontFemale      string `json:"front_female"`
+			FrontShiny       string `json:"front_shiny"`
+			FrontShinyFemale string `json:"front_shiny_female"`
+		} `json:"showdown"`
+	} `json:"other"`
+	Versions struct {
+		GenerationI struct {
+			RedBlue struct {
+				BackDefault      string `json:"back_default"`
+				BackGray         string `json:"back_gray"`
+				BackTransparent  string `json:"back_transparent"`
+				FrontDefault     string `json:"front_default"`
+				FrontGray        string `json:"front_gray"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"red-blue"`
+			Yellow struct {
+				BackDefault      string `json:"back_default"`
+				BackGray         string `json:"back_gray"`
+				BackTransparent  string `json:"back_transparent"`
+				FrontDefault     string `json:"front_default"`
+				FrontGray        string `json:"front_gray"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"yellow"`
+		} `json:"generation-i"`
+		GenerationIi struct {
+			Crystal struct {
+				BackDefault           string `json:"back_default"`
+				BackShiny             string `json:"back_shiny"`
+				BackShinyTransparent  string `json:"back_shiny_transparent"`
+				BackTransparent       string `json:"back_transparent"`
+				FrontDefault          string `json:"front_default"`
+				FrontShiny            string `json:"front_shiny"`
+				FrontShinyTransparent string `json:"front_shiny_transparent"`
+				FrontTransparent      string `json:"front_transparent"`
+			} `json:"crystal"`
+			Gold struct {
+				BackDefault      string `json:"back_default"`
+				BackShiny        string `json:"back_shiny"`
+				FrontDefault     string `json:"front_default"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"gold"`
+			Silver struct {
+				BackDefault      string `json:"back_default"`
+				BackShiny        string `json:"back_shiny"`
+				FrontDefault     string `json:"front_default"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontTransparent string `json:"front_transparent"`
+			} `json:"silver"`
+		} `json:"generation-ii"`
+		GenerationIii struct {
+			Emerald struct {
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"emerald"`
+			FireredLeafgreen struct {
+				BackDefault  string `json:"back_default"`
+				BackShiny    string `json:"back_shiny"`
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"firered-leafgreen"`
+			RubySapphire struct {
+				BackDefault  string `json:"back_default"`
+				BackShiny    string `json:"back_shiny"`
+				FrontDefault string `json:"front_default"`
+				FrontShiny   string `json:"front_shiny"`
+			} `json:"ruby-sapphire"`
+		} `json:"generation-iii"`
+		GenerationIv struct {
+			DiamondPearl struct {
+				BackDefault      string `json:"back_default"`
+				BackFemale       string `json:"back_female"`
+				BackShiny        string `json:"back_shiny"`
+				BackShinyFemale  string `json:"back_shiny_female"`
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"diamond-pearl"`
+			HeartgoldSoulsilver struct {
+				BackDefault      string `json:"back_default"`
+				BackFemale       string `json:"back_female"`
+				BackShiny        string `json:"back_shiny"`
+				BackShinyFemale  string `json:"back_shiny_female"`
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"heartgold-soulsilver"`
+			Platinum struct {
+				BackDefault      string `json:"back_default"`
+				BackFemale       string `json:"back_female"`
+				BackShiny        string `json:"back_shiny"`
+				BackShinyFemale  string `json:"back_shiny_female"`
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"platinum"`
+		} `json:"generation-iv"`
+		GenerationV struct {
+			BlackWhite struct {
+				Animated struct {
+					BackDefault      string `json:"back_default"`
+					BackFemale       string `json:"back_female"`
+					BackShiny        string `json:"back_shiny"`
+					BackShinyFemale  string `json:"back_shiny_female"`
+					FrontDefault     string `json:"front_default"`
+					FrontFemale      string `json:"front_female"`
+					FrontShiny       string `json:"front_shiny"`
+					FrontShinyFemale string `json:"front_shiny_female"`
+				} `json:"animated"`
+				BackDefault      string `json:"back_default"`
+				BackFemale       string `json:"back_female"`
+				BackShiny        string `json:"back_shiny"`
+				BackShinyFemale  string `json:"back_shiny_female"`
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"black-white"`
+		} `json:"generation-v"`
+		GenerationVi struct {
+			OmegarubyAlphasapphire struct {
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"omegaruby-alphasapphire"`
+			XY struct {
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"x-y"`
+		} `json:"generation-vi"`
+		GenerationVii struct {
+			Icons struct {
+				FrontDefault string `json:"front_default"`
+				FrontFemale  any    `json:"front_female"`
+			} `json:"icons"`
+			UltraSunUltraMoon struct {
+				FrontDefault     string `json:"front_default"`
+				FrontFemale      string `json:"front_female"`
+				FrontShiny       string `json:"front_shiny"`
+				FrontShinyFemale string `json:"front_shiny_female"`
+			} `json:"ultra-sun-ultra-moon"`
+		} `json:"generation-vii"`
+		GenerationViii struct {
+			Icons struct {
+				FrontDefault string `json:"front_default"`
+				FrontFemale  any    `json:"front_female"`
+			} `json:"icons"`
+		} `json:"generation-viii"`
+	} `json:"versions"`
+}
+
+type PokemonMove struct {
+	Move struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"move"`
+	VersionGroupDetails []struct {
+		LevelLearnedAt  int `json:"level_learned_at"`
+		MoveLearnMethod struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"move_learn_method"`
+		Order        any `json:"order"`
+		VersionGroup struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version_group"`
+	} `json:"version_group_details"`
+}
+
+type PokemonGameIndex struct {
+	GameIndex int `json:"game_index"`
+	Version   struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"version"`
+}
+
+// DecodeSprites decodes the sprite URLs for every generation
+func (p Pokemon) DecodeSprites() (PokemonSprites, error) {
+	var sprites PokemonSprites
+	if err := decodeSection(p.Sprites, &sprites); err != nil {
+		return sprites, fmt.Errorf("Error decoding sprites: %w", err)
+	}
+	return sprites, nil
+}
+
+// DecodeMoves decodes the full move list
+func (p Pokemon) DecodeMoves() ([]PokemonMove, error) {
+	var moves []PokemonMove
+	if err := decodeSection(p.Moves, &moves); err != nil {
+		return nil, fmt.Errorf("Error decoding moves: %w", err)
+	}
+	return moves, nil
+}
+
+// DecodeGameIndices decodes the per version game indices
+func (p Pokemon) DecodeGameIndices() ([]PokemonGameIndex, error) {
+	var indices []PokemonGameIndex
+	if err := decodeSection(p.GameIndices, &indices); err != nil {
+		return nil, fmt.Errorf("Error decoding game indices: %w", err)
+	}
+	return indices, nil
+}
+
+// A missing section decodes to the zero value rather than an error
+func decodeSection(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
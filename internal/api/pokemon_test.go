@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// eagerPokemon decodes the sprites, moves and game indices up front, as Pokemon
+// did before they were kept as raw JSON. It is the baseline for the decode benchmarks.
+type eagerPokemon struct {
+	Pokemon
+	Sprites     PokemonSprites     `json:"sprites"`
+	Moves       []PokemonMove      `json:"moves"`
+	GameIndices []PokemonGameIndex `json:"game_indices"`
+}
+
+func loadPokemonFixture(tb testing.TB) []byte {
+	tb.Helper()
+	data, err := os.ReadFile("testdata/pikachu.json")
+	if err != nil {
+		tb.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestPokemonLeanDecode(t *testing.T) {
+	data := loadPokemonFixture(t)
+
+	var lean Pokemon
+	if err := json.Unmarshal(data, &lean); err != nil {
+		t.Fatalf("failed to decode lean Pokemon: %v", err)
+	}
+	var eager eagerPokemon
+	if err := json.Unmarshal(data, &eager); err != nil {
+		t.Fatalf("failed to decode eager Pokemon: %v", err)
+	}
+
+	if lean.Name != eager.Name || lean.Height != eager.Height || lean.Weight != eager.Weight || lean.BaseExperience != eager.BaseExperience {
+		t.Errorf("core fields differ between lean and eager decode")
+	}
+	if len(lean.Stats) != len(eager.Stats) || len(lean.Types) != len(eager.Types) {
+		t.Errorf("expected stats and types to be decoded eagerly")
+	}
+
+	sprites, err := lean.DecodeSprites()
+	if err != nil {
+		t.Fatalf("unexpected error decoding sprites: %v", err)
+	}
+	if sprites.FrontDefault != eager.Sprites.FrontDefault {
+		t.Errorf("expected front sprite '%s', got '%s'", eager.Sprites.FrontDefault, sprites.FrontDefault)
+	}
+	if sprites.Versions.GenerationIv.Platinum.FrontShiny != eager.Sprites.Versions.GenerationIv.Platinum.FrontShiny {
+		t.Errorf("nested version sprites differ between lean and eager decode")
+	}
+
+	moves, err := lean.DecodeMoves()
+	if err != nil {
+		t.Fatalf("unexpected error decoding moves: %v", err)
+	}
+	if len(moves) != len(eager.Moves) {
+		t.Errorf("expected %d moves, got %d", len(eager.Moves), len(moves))
+	}
+
+	indices, err := lean.DecodeGameIndices()
+	if err != nil {
+		t.Fatalf("unexpected error decoding game indices: %v", err)
+	}
+	if len(indices) != len(eager.GameIndices) {
+		t.Errorf("expected %d game indices, got %d", len(eager.GameIndices), len(indices))
+	}
+}
+
+func TestPokemonMissingSections(t *testing.T) {
+	var mon Pokemon
+	if err := json.Unmarshal([]byte(`{"name":"ditto"}`), &mon); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := mon.DecodeSprites(); err != nil {
+		t.Errorf("expected missing sprites to decode to zero value, got: %v", err)
+	}
+	moves, err := mon.DecodeMoves()
+	if err != nil || moves != nil {
+		t.Errorf("expected no moves and no error, got %v, %v", moves, err)
+	}
+}
+
+// Compare with: go test -bench Decode -benchmem ./internal/api/
+func BenchmarkDecodeEagerPokemon(b *testing.B) {
+	data := loadPokemonFixture(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		var mon eagerPokemon
+		if err := json.Unmarshal(data, &mon); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeLeanPokemon(b *testing.B) {
+	data := loadPokemonFixture(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		var mon Pokemon
+		if err := json.Unmarshal(data, &mon); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeLeanPokemonWithSprites(b *testing.B) {
+	data := loadPokemonFixture(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		var mon Pokemon
+		if err := json.Unmarshal(data, &mon); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := mon.DecodeSprites(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
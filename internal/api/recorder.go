@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecorderMode controls how a Recorder treats requests
+type RecorderMode int
+
+const (
+	// ModeReplay serves recorded interactions and records any request it has not seen yet
+	ModeReplay RecorderMode = iota
+	// ModeRecord always goes to the network, replacing the recorded interactions it
+	// repeats and keeping the rest of the cassette
+	ModeRecord
+	// ModeReplayOnly serves recorded interactions and fails on anything unrecorded
+	ModeReplayOnly
+)
+
+// ErrUnrecorded is returned in ModeReplayOnly when a request has no recorded interaction
+var ErrUnrecorded = errors.New("no recorded interaction")
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records PokeAPI interactions to a
+// cassette file and replays them later
+type Recorder struct {
+	path         string
+	mode         RecorderMode
+	next         http.RoundTripper
+	mu           sync.Mutex
+	interactions []Interaction
+	dirty        bool
+	unrecorded   []string
+}
+
+// NewRecorder loads the cassette at path (if it exists) and returns a Recorder
+// that falls back to next for real requests. A nil next uses http.DefaultTransport.
+func NewRecorder(path string, mode RecorderMode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		path: path,
+		mode: mode,
+		next: next,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && mode != ModeReplayOnly {
+			return r, nil
+		}
+		return nil, fmt.Errorf("Error reading cassette: %w", err)
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling cassette: %w", err)
+	}
+	r.interactions = c.Interactions
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	if r.mode != ModeRecord {
+		if in, ok := r.find(req.Method, req.URL.String()); ok {
+			r.mu.Unlock()
+			return in.response(req)
+		}
+	}
+	r.mu.Unlock()
+
+	if r.mode == ModeReplayOnly {
+		r.mu.Lock()
+		r.unrecorded = append(r.unrecorded, req.Method+" "+req.URL.String())
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s %s", ErrUnrecorded, req.Method, req.URL)
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+	}
+	in.setBody(body)
+
+	r.mu.Lock()
+	r.replace(in)
+	r.dirty = true
+	r.mu.Unlock()
+
+	return in.response(req)
+}
+
+// Unrecorded lists the requests ModeReplayOnly could not serve, so tests can catch
+// them even when the code making them falls back without reporting the error
+func (r *Recorder) Unrecorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.unrecorded...)
+}
+
+// Save writes the cassette back to disk if anything new was recorded
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error marshalling cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("Error creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("Error writing cassette: %w", err)
+	}
+	r.dirty = false
+	return nil
+}
+
+func (r *Recorder) find(method, url string) (Interaction, bool) {
+	for _, in := range r.interactions {
+		if in.Method == method && in.URL == url {
+			return in, true
+		}
+	}
+	return Interaction{}, false
+}
+
+func (r *Recorder) replace(in Interaction) {
+	for i := range r.interactions {
+		if r.interactions[i].Method == in.Method && r.interactions[i].URL == in.URL {
+			r.interactions[i] = in
+			return
+		}
+	}
+	r.interactions = append(r.interactions, in)
+}
+
+// Keep JSON bodies readable in the cassette and fall back to base64 for binary data
+func (in *Interaction) setBody(body []byte) {
+	if utf8.Valid(body) {
+		in.Body = string(body)
+		return
+	}
+	in.Body = base64.StdEncoding.EncodeToString(body)
+	in.BodyEncoding = "base64"
+}
+
+func (in Interaction) response(req *http.Request) (*http.Response, error) {
+	body := []byte(in.Body)
+	if in.BodyEncoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(in.Body)
+		if err != nil {
+			return nil, fmt.Errorf("Error decoding recorded body: %w", err)
+		}
+		body = decoded
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"pokedexcli/internal/pokecache"
+	"testing"
+	"time"
+)
+
+func TestRecorderRecordThenReplay(t *testing.T) {
+	serverCallCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverCallCount++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// Record the interaction against the test server
+	rec, err := NewRecorder(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating recorder: %v", err)
+	}
+	SetTransport(rec)
+	defer SetTransport(nil)
+
+	if _, err := ApiRequest(server.URL, pokecache.NewCache(5*time.Minute)); err != nil {
+		t.Fatalf("unexpected error recording: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("unexpected error saving cassette: %v", err)
+	}
+
+	// Replay it with the server gone
+	server.Close()
+	replay, err := NewRecorder(path, ModeReplayOnly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error loading cassette: %v", err)
+	}
+	SetTransport(replay)
+
+	body, err := ApiRequest(server.URL, pokecache.NewCache(5*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error replaying: %v", err)
+	}
+	if string(body) != `{"name":"pikachu"}` {
+		t.Errorf("expected recorded body, got '%s'", string(body))
+	}
+	if serverCallCount != 1 {
+		t.Errorf("server was called %d times, expected 1", serverCallCount)
+	}
+}
+
+func TestRecorderReplayOnlyUnrecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating recorder: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("unexpected error saving empty cassette: %v", err)
+	}
+
+	// Nothing was recorded so the file should not exist yet
+	if _, err := NewRecorder(path, ModeReplayOnly, nil); err == nil {
+		t.Errorf("expected error loading a missing cassette in replay-only mode")
+	}
+
+	rec = &Recorder{path: path, mode: ModeReplayOnly, next: http.DefaultTransport}
+	req, _ := http.NewRequest(http.MethodGet, "https://pokeapi.co/api/v2/pokemon/ditto", nil)
+	_, err = rec.RoundTrip(req)
+	if !errors.Is(err, ErrUnrecorded) {
+		t.Errorf("expected ErrUnrecorded, got: %v", err)
+	}
+	if missed := rec.Unrecorded(); len(missed) != 1 || missed[0] != "GET https://pokeapi.co/api/v2/pokemon/ditto" {
+		t.Errorf("expected the request to be listed as unrecorded, got %v", missed)
+	}
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0x00}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(png)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, _ := NewRecorder(path, ModeReplay, nil)
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := rec.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error recording: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("unexpected error saving cassette: %v", err)
+	}
+
+	replay, err := NewRecorder(path, ModeReplayOnly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error loading cassette: %v", err)
+	}
+	res, err := replay.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error replaying: %v", err)
+	}
+	defer res.Body.Close()
+
+	buf := make([]byte, 32)
+	n, _ := res.Body.Read(buf)
+	if string(buf[:n]) != string(png) {
+		t.Errorf("binary body did not survive the cassette round trip")
+	}
+}
+
+func TestRecorderRecordKeepsOtherInteractions(t *testing.T) {
+	body := `{"name":"pikachu"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	record := func(url string) {
+		t.Helper()
+		rec, err := NewRecorder(path, ModeRecord, nil)
+		if err != nil {
+			t.Fatalf("unexpected error creating recorder: %v", err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if _, err := rec.RoundTrip(req); err != nil {
+			t.Fatalf("unexpected error recording: %v", err)
+		}
+		if err := rec.Save(); err != nil {
+			t.Fatalf("unexpected error saving cassette: %v", err)
+		}
+	}
+
+	// Each recording session, like each test under -record, adds to the same cassette
+	record(server.URL + "/pikachu")
+	record(server.URL + "/ditto")
+	body = `{"name":"pikachu","updated":true}`
+	record(server.URL + "/pikachu")
+
+	replay, err := NewRecorder(path, ModeReplayOnly, nil)
+	if err != nil {
+		t.Fatalf("unexpected error loading cassette: %v", err)
+	}
+	if len(replay.interactions) != 2 {
+		t.Fatalf("expected 2 interactions, got %d", len(replay.interactions))
+	}
+	if in, _ := replay.find(http.MethodGet, server.URL+"/pikachu"); in.Body != body {
+		t.Errorf("expected the re-recorded body, got '%s'", in.Body)
+	}
+	if _, ok := replay.find(http.MethodGet, server.URL+"/ditto"); !ok {
+		t.Errorf("expected the earlier recording to be kept")
+	}
+}
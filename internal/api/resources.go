@@ -0,0 +1,30 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NamedResource is the name and URL reference PokeAPI uses to link resources
+type NamedResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ID returns the numeric id at the end of the resource URL, or 0 if there is none
+func (r NamedResource) ID() int {
+	parts := strings.Split(strings.TrimSuffix(r.URL, "/"), "/")
+	id, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// ResourceList is a page of any PokeAPI list endpoint
+type ResourceList struct {
+	Count    int             `json:"count"`
+	Next     string          `json:"next"`
+	Previous string          `json:"previous"`
+	Results  []NamedResource `json:"results"`
+}
@@ -0,0 +1,20 @@
+package api
+
+import "testing"
+
+func TestNamedResourceID(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected int
+	}{
+		{"https://pokeapi.co/api/v2/pokemon/25/", 25},
+		{"https://pokeapi.co/api/v2/location-area/3", 3},
+		{"https://pokeapi.co/api/v2/pokemon/pikachu", 0},
+		{"", 0},
+	}
+	for _, c := range cases {
+		if got := (NamedResource{URL: c.url}).ID(); got != c.expected {
+			t.Errorf("ID(%s) = %d, expected %d", c.url, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+// PokemonSpecies groups every variety of a Pokemon, e.g. charizard and its mega evolutions
+type PokemonSpecies struct {
+	ID                int           `json:"id"`
+	Name              string        `json:"name"`
+	Order             int           `json:"order"`
+	IsBaby            bool          `json:"is_baby"`
+	IsLegendary       bool          `json:"is_legendary"`
+	IsMythical        bool          `json:"is_mythical"`
+	HasGenderDiffs    bool          `json:"has_gender_differences"`
+	FormsSwitchable   bool          `json:"forms_switchable"`
+	Generation        NamedResource `json:"generation"`
+	Names             []Name        `json:"names"`
+	FlavorTextEntries []FlavorText  `json:"flavor_text_entries"`
+	Varieties         []struct {
+		IsDefault bool          `json:"is_default"`
+		Pokemon   NamedResource `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+// PokemonForm is a single form of a Pokemon variety, including purely cosmetic ones
+type PokemonForm struct {
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	FormName     string        `json:"form_name"`
+	FormOrder    int           `json:"form_order"`
+	FormNames    []Name        `json:"form_names"`
+	Names        []Name        `json:"names"`
+	IsDefault    bool          `json:"is_default"`
+	IsBattleOnly bool          `json:"is_battle_only"`
+	IsMega       bool          `json:"is_mega"`
+	Pokemon      NamedResource `json:"pokemon"`
+	VersionGroup NamedResource `json:"version_group"`
+	Types        []struct {
+		Slot int           `json:"slot"`
+		Type NamedResource `json:"type"`
+	} `json:"types"`
+}
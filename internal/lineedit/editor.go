@@ -0,0 +1,409 @@
+// Package lineedit reads lines from a terminal with cursor movement, history,
+// reverse search and tab completion. When the input is not a terminal it falls
+// back to reading plain lines.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C is pressed
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns candidates for the word under the cursor. line is the text
+// before the cursor and the word being completed is everything after its last space.
+type Completer func(line string) []string
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEsc       = 27
+	keyBackspace = 127
+)
+
+// Escape sequences are decoded into keys outside the rune range
+const (
+	keyUp = utf8.MaxRune + 1 + iota
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// Editor reads lines from in and draws the line being edited on out
+type Editor struct {
+	History  *History
+	Complete Completer
+
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	terminal bool
+}
+
+// New creates an Editor. Line editing is only enabled when in is a terminal.
+func New(in io.Reader, out io.Writer) *Editor {
+	e := &Editor{
+		History: NewHistory(1000),
+		in:      bufio.NewReader(in),
+		out:     out,
+	}
+	if f, ok := in.(*os.File); ok && isTerminal(int(f.Fd())) {
+		e.fd, e.terminal = int(f.Fd()), true
+	}
+	return e
+}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return isTerminal(int(f.Fd()))
+}
+
+// ReadLine prints the prompt and returns the next line without its newline.
+// It returns io.EOF at the end of input or on Ctrl-D with an empty line.
+// Lines entered at a terminal are added to the history.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		return e.readPlain(prompt)
+	}
+
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	line, err := e.edit(prompt)
+	restore()
+	fmt.Fprint(e.out, "\n")
+
+	if err == nil {
+		if histErr := e.History.Add(line); histErr != nil {
+			return line, histErr
+		}
+	}
+	return line, err
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// lineState is the line being edited and where the cursor is in it
+type lineState struct {
+	prompt    string
+	buf       []rune
+	pos       int
+	histIndex int
+	pending   []rune
+}
+
+// edit runs the key loop until Enter, Ctrl-C or end of input
+func (e *Editor) edit(prompt string) (string, error) {
+	s := &lineState{prompt: prompt, histIndex: e.History.Len()}
+	e.refresh(s)
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			if err == io.EOF && len(s.buf) > 0 {
+				return string(s.buf), nil
+			}
+			return "", err
+		}
+
+		switch key {
+		case keyCR, keyLF:
+			return string(s.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(s.buf) == 0 {
+				return "", io.EOF
+			}
+			s.deleteAt(s.pos)
+		case keyBackspace, keyCtrlH:
+			if s.pos > 0 {
+				s.pos--
+				s.deleteAt(s.pos)
+			}
+		case keyDelete:
+			s.deleteAt(s.pos)
+		case keyCtrlA, keyHome:
+			s.pos = 0
+		case keyCtrlE, keyEnd:
+			s.pos = len(s.buf)
+		case keyCtrlB, keyLeft:
+			if s.pos > 0 {
+				s.pos--
+			}
+		case keyCtrlF, keyRight:
+			if s.pos < len(s.buf) {
+				s.pos++
+			}
+		case keyCtrlK:
+			s.buf = s.buf[:s.pos]
+		case keyCtrlU:
+			s.buf = append([]rune{}, s.buf[s.pos:]...)
+			s.pos = 0
+		case keyCtrlW:
+			start := s.pos
+			for start > 0 && s.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && s.buf[start-1] != ' ' {
+				start--
+			}
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case keyCtrlP, keyUp:
+			e.historyMove(s, -1)
+		case keyCtrlN, keyDown:
+			e.historyMove(s, 1)
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyTab:
+			e.completeWord(s)
+		case keyCtrlR:
+			line, done, err := e.reverseSearch(s)
+			if err != nil || done {
+				return line, err
+			}
+		default:
+			if key >= ' ' && key <= utf8.MaxRune && key != keyBackspace {
+				s.insert([]rune{key})
+			}
+		}
+		e.refresh(s)
+	}
+}
+
+// readKey reads one key press, decoding arrow, home, end and delete escape sequences
+func (e *Editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEsc {
+		return r, err
+	}
+
+	next, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if next != '[' && next != 'O' {
+		return keyUnknown, nil
+	}
+
+	//Collect parameter bytes up to the final byte of the sequence
+	params := ""
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			switch {
+			case b == 'A':
+				return keyUp, nil
+			case b == 'B':
+				return keyDown, nil
+			case b == 'C':
+				return keyRight, nil
+			case b == 'D':
+				return keyLeft, nil
+			case b == 'H':
+				return keyHome, nil
+			case b == 'F':
+				return keyEnd, nil
+			case b == '~' && (params == "1" || params == "7"):
+				return keyHome, nil
+			case b == '~' && (params == "4" || params == "8"):
+				return keyEnd, nil
+			case b == '~' && params == "3":
+				return keyDelete, nil
+			}
+			return keyUnknown, nil
+		}
+		params += string(b)
+	}
+}
+
+func (s *lineState) insert(runes []rune) {
+	buf := make([]rune, 0, len(s.buf)+len(runes))
+	buf = append(buf, s.buf[:s.pos]...)
+	buf = append(buf, runes...)
+	s.buf = append(buf, s.buf[s.pos:]...)
+	s.pos += len(runes)
+}
+
+func (s *lineState) deleteAt(i int) {
+	if i < len(s.buf) {
+		s.buf = append(s.buf[:i], s.buf[i+1:]...)
+	}
+}
+
+// refresh redraws the prompt and line and puts the cursor back in place
+func (e *Editor) refresh(s *lineState) {
+	e.draw(s.prompt+string(s.buf), utf8.RuneCountInString(s.prompt)+s.pos)
+}
+
+func (e *Editor) draw(text string, cursor int) {
+	fmt.Fprintf(e.out, "\r%s\x1b[K\r", text)
+	if cursor > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", cursor)
+	}
+}
+
+// historyMove steps through the history, keeping the line being typed to come back to
+func (e *Editor) historyMove(s *lineState, step int) {
+	index := s.histIndex + step
+	if index < 0 || index > e.History.Len() {
+		return
+	}
+	if s.histIndex == e.History.Len() {
+		s.pending = append([]rune{}, s.buf...)
+	}
+
+	s.histIndex = index
+	if index == e.History.Len() {
+		s.buf = append([]rune{}, s.pending...)
+	} else {
+		s.buf = []rune(e.History.at(index))
+	}
+	s.pos = len(s.buf)
+}
+
+// completeWord completes the word before the cursor to the longest prefix all
+// candidates share, and lists the candidates when that adds nothing
+func (e *Editor) completeWord(s *lineState) {
+	if e.Complete == nil {
+		return
+	}
+	before := string(s.buf[:s.pos])
+	word := before[strings.LastIndexAny(before, " \t")+1:]
+
+	matches := []string{}
+	for _, candidate := range e.Complete(before) {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	prefix := commonPrefix(matches)
+	if len(matches) == 1 {
+		prefix += " "
+	}
+	if len(prefix) > len(word) {
+		s.insert([]rune(prefix[len(word):]))
+		return
+	}
+
+	fmt.Fprintf(e.out, "\n%s\n", strings.Join(matches, "  "))
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// reverseSearch searches the history for lines containing what is typed, newest
+// first. Ctrl-R finds the next older match, Enter runs the match, Ctrl-G or Ctrl-C
+// cancels and any other key leaves the match on the line for editing.
+func (e *Editor) reverseSearch(s *lineState) (string, bool, error) {
+	query := []rune{}
+	index := e.History.Len()
+	match := ""
+	failed := false
+
+	search := func(from int) {
+		for i := from; i >= 0; i-- {
+			if strings.Contains(e.History.at(i), string(query)) {
+				index, match, failed = i, e.History.at(i), false
+				return
+			}
+		}
+		failed = true
+	}
+
+	for {
+		label := "(reverse-i-search)"
+		if failed {
+			label = "(failed reverse-i-search)"
+		}
+		text := fmt.Sprintf("%s`%s': %s", label, string(query), match)
+		e.draw(text, utf8.RuneCountInString(text))
+
+		key, err := e.readKey()
+		if err != nil {
+			return "", true, err
+		}
+
+		switch key {
+		case keyCR, keyLF:
+			if match != "" {
+				s.buf, s.pos = []rune(match), utf8.RuneCountInString(match)
+			}
+			e.refresh(s)
+			return string(s.buf), true, nil
+		case keyCtrlG, keyCtrlC:
+			return "", false, nil
+		case keyCtrlR:
+			if len(query) > 0 {
+				search(index - 1)
+			}
+		case keyBackspace, keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				search(e.History.Len() - 1)
+			}
+		default:
+			if key >= ' ' && key <= utf8.MaxRune && key != keyBackspace {
+				query = append(query, key)
+				search(min(index, e.History.Len()-1))
+				continue
+			}
+			if match != "" {
+				s.buf, s.pos = []rune(match), utf8.RuneCountInString(match)
+			}
+			return "", false, nil
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package lineedit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, history ...string) (*Editor, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	e := New(strings.NewReader(input), out)
+	for _, line := range history {
+		e.History.Add(line)
+	}
+	return e, out
+}
+
+func TestEditKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "map\r", "map"},
+		{"insert at start", "ctch\x01\x06a\r", "catch"},
+		{"arrow keys and backspace", "abc\x1b[D\x1b[D\x7f\r", "bc"},
+		{"home, end and delete", "xmap\x1b[H\x1b[3~\x1b[F!\r", "map!"},
+		{"delete word", "explore pastoria\x17map\r", "explore map"},
+		{"kill to end", "catch magikarp\x01\x06\x06\x06\x06\x06\x0b\r", "catch"},
+		{"kill to start", "oops catch\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x15\r", "catch"},
+		{"multibyte", "pokémon\x7f\x7f\x7fn\r", "pokén"},
+	}
+
+	for _, c := range cases {
+		e, _ := newTestEditor(c.input)
+		line, err := e.edit("> ")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if line != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, line)
+		}
+	}
+}
+
+func TestEditInterruptAndEOF(t *testing.T) {
+	e, _ := newTestEditor("catch\x03")
+	if _, err := e.edit("> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted, got %v", err)
+	}
+
+	e, _ = newTestEditor("\x04")
+	if _, err := e.edit("> "); err != io.EOF {
+		t.Errorf("expected io.EOF on Ctrl-D, got %v", err)
+	}
+
+	e, _ = newTestEditor("map")
+	if line, err := e.edit("> "); err != nil || line != "map" {
+		t.Errorf("expected unterminated line to be returned, got %q, %v", line, err)
+	}
+}
+
+func TestEditHistory(t *testing.T) {
+	e, _ := newTestEditor("\x1b[A\x1b[A\x1b[B\r", "map", "explore pastoria-city-area")
+	if line, _ := e.edit("> "); line != "explore pastoria-city-area" {
+		t.Errorf("expected previous line, got %q", line)
+	}
+
+	e, _ = newTestEditor("ca\x10\x10\x10\x0e\x0e\r", "map", "mapb")
+	if line, _ := e.edit("> "); line != "ca" {
+		t.Errorf("expected typed line to be restored, got %q", line)
+	}
+}
+
+func TestReverseSearch(t *testing.T) {
+	history := []string{"catch magikarp", "map", "catch gyarados"}
+
+	e, _ := newTestEditor("\x12catch\r", history...)
+	if line, _ := e.edit("> "); line != "catch gyarados" {
+		t.Errorf("expected newest match, got %q", line)
+	}
+
+	e, _ = newTestEditor("\x12catch\x12\r", history...)
+	if line, _ := e.edit("> "); line != "catch magikarp" {
+		t.Errorf("expected older match after second Ctrl-R, got %q", line)
+	}
+
+	e, _ = newTestEditor("\x12ma\x06!\r", history...)
+	if line, _ := e.edit("> "); line != "map!" {
+		t.Errorf("expected match to be left for editing, got %q", line)
+	}
+
+	e, _ = newTestEditor("explore\x12ma\x07\r", history...)
+	if line, _ := e.edit("> "); line != "explore" {
+		t.Errorf("expected Ctrl-G to restore the line, got %q", line)
+	}
+
+	e, out := newTestEditor("\x12zzz\x07\r", history...)
+	e.edit("> ")
+	if !strings.Contains(out.String(), "(failed reverse-i-search)`zzz'") {
+		t.Errorf("expected failed search to be shown, got %q", out.String())
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	complete := func(line string) []string {
+		if !strings.Contains(line, " ") {
+			return []string{"catch", "cry", "map", "mapb"}
+		}
+		return []string{"magikarp", "magmar", "gyarados"}
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"ca\t\r", "catch "},
+		{"catch magi\t\r", "catch magikarp "},
+		{"catch gy\x01\x06\x06\x06\x06\x06\x06\x06\x06\t\r", "catch gyarados "},
+		{"ma\t\r", "map"},
+		{"catch z\t\r", "catch z"},
+	}
+	for _, c := range cases {
+		e, _ := newTestEditor(c.input)
+		e.Complete = complete
+		if line, _ := e.edit("> "); line != c.expected {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, line)
+		}
+	}
+
+	e, out := newTestEditor("catch mag\t\r")
+	e.Complete = complete
+	e.edit("> ")
+	if !strings.Contains(out.String(), "\nmagikarp  magmar\n") {
+		t.Errorf("expected ambiguous candidates to be listed, got %q", out.String())
+	}
+}
+
+func TestReadLineNotTerminal(t *testing.T) {
+	e, out := newTestEditor("map\r\nexplore pastoria-city-area")
+
+	for _, expected := range []string{"map", "explore pastoria-city-area"} {
+		line, err := e.ReadLine("Pokedex > ")
+		if err != nil || line != expected {
+			t.Errorf("expected %q, got %q, %v", expected, line, err)
+		}
+	}
+	if _, err := e.ReadLine("Pokedex > "); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if out.String() != "Pokedex > Pokedex > Pokedex > " {
+		t.Errorf("unexpected prompt output %q", out.String())
+	}
+	if e.History.Len() != 0 {
+		t.Errorf("expected piped lines to stay out of the history")
+	}
+}
@@ -0,0 +1,113 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// History is the list of previously entered lines, oldest first. When it was
+// loaded from a file every new line is appended to that file as well.
+type History struct {
+	entries []string
+	max     int
+	path    string
+}
+
+// NewHistory creates an in-memory history that keeps at most max lines
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// LoadHistory reads the history file at path, if there is one, and keeps
+// appending to it. The returned History is usable even when an error is returned.
+func LoadHistory(path string, max int) (*History, error) {
+	h := &History{max: max, path: path}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("Error opening history file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return h, fmt.Errorf("Error reading history file: %w", err)
+	}
+
+	//Rewrite the file once it grows past the limit so it does not grow forever
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+		if err := h.rewrite(); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// Add records a line, skipping blank lines and repeats of the previous line
+func (h *History) Add(line string) error {
+	line = strings.TrimRight(line, " \t")
+	if line == "" {
+		return nil
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return nil
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return fmt.Errorf("Error creating history directory: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Error opening history file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("Error writing history file: %w", err)
+	}
+	return nil
+}
+
+// Entries returns a copy of the history, oldest first
+func (h *History) Entries() []string {
+	return append([]string{}, h.entries...)
+}
+
+// Len returns the number of lines in the history
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// at returns the i-th oldest line
+func (h *History) at(i int) string {
+	return h.entries[i]
+}
+
+func (h *History) rewrite() error {
+	data := strings.Join(h.entries, "\n") + "\n"
+	if err := os.WriteFile(h.path, []byte(data), 0600); err != nil {
+		return fmt.Errorf("Error writing history file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package lineedit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedexcli", "history")
+
+	h, err := LoadHistory(path, 100)
+	if err != nil {
+		t.Fatalf("unexpected error loading missing file: %v", err)
+	}
+	for _, line := range []string{"map", "map", "  ", "explore pastoria-city-area", "catch magikarp"} {
+		if err := h.Add(line); err != nil {
+			t.Fatalf("unexpected error adding %q: %v", line, err)
+		}
+	}
+
+	reloaded, err := LoadHistory(path, 100)
+	if err != nil {
+		t.Fatalf("unexpected error reloading: %v", err)
+	}
+	expected := []string{"map", "explore pastoria-city-area", "catch magikarp"}
+	entries := reloaded.Entries()
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := LoadHistory(path, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries := h.Entries(); len(entries) != 2 || entries[0] != "c" || entries[1] != "d" {
+		t.Errorf("expected the newest two lines, got %v", entries)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "c\nd\n" {
+		t.Errorf("expected the file to be trimmed, got %q", data)
+	}
+
+	h.Add("e")
+	if entries := h.Entries(); len(entries) != 2 || entries[1] != "e" {
+		t.Errorf("expected the oldest line to be dropped, got %v", entries)
+	}
+}
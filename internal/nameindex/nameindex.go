@@ -0,0 +1,155 @@
+package nameindex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxSuggestions is how many close matches a LookupError offers
+const maxSuggestions = 3
+
+// Entry is a single resource name and its PokeAPI id
+type Entry struct {
+	Name string
+	ID   int
+}
+
+// Index resolves user input to resource names by exact name, id or unique prefix
+type Index struct {
+	names  []string
+	byName map[string]bool
+	byID   map[int]string
+}
+
+// LookupError is returned when a query does not resolve to exactly one name
+type LookupError struct {
+	Query       string
+	Ambiguous   bool
+	Suggestions []string
+}
+
+func (e *LookupError) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("'%s' matches more than one name", e.Query)
+	}
+	return fmt.Sprintf("'%s' not found", e.Query)
+}
+
+// DidYouMean formats the suggestions for an error message, or returns "" when there are none
+func (e *LookupError) DidYouMean() string {
+	if len(e.Suggestions) == 0 {
+		return ""
+	}
+	return "Did you mean: " + strings.Join(e.Suggestions, ", ") + "?"
+}
+
+func New(entries []Entry) *Index {
+	ix := &Index{
+		names:  make([]string, 0, len(entries)),
+		byName: make(map[string]bool, len(entries)),
+		byID:   make(map[int]string, len(entries)),
+	}
+	for _, e := range entries {
+		ix.names = append(ix.names, e.Name)
+		ix.byName[e.Name] = true
+		if e.ID > 0 {
+			ix.byID[e.ID] = e.Name
+		}
+	}
+	sort.Strings(ix.names)
+	return ix
+}
+
+// Len returns the number of names in the index
+func (ix *Index) Len() int {
+	return len(ix.names)
+}
+
+// Resolve turns a name, id or unique prefix into a full name
+func (ix *Index) Resolve(query string) (string, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if ix.byName[query] {
+		return query, nil
+	}
+
+	if id, err := strconv.Atoi(strings.TrimPrefix(query, "#")); err == nil {
+		if name, exists := ix.byID[id]; exists {
+			return name, nil
+		}
+		return "", &LookupError{Query: query}
+	}
+
+	matches := ix.prefixMatches(query)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return "", &LookupError{Query: query, Ambiguous: true, Suggestions: matches[:min(len(matches), maxSuggestions)]}
+	}
+
+	return "", &LookupError{Query: query, Suggestions: ix.Suggest(query, maxSuggestions)}
+}
+
+// Suggest returns up to n names closest to query by edit distance
+func (ix *Index) Suggest(query string, n int) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	// Allow roughly one typo per three characters
+	limit := max(2, len(query)/3)
+	candidates := []candidate{}
+	for _, name := range ix.names {
+		if d := Distance(query, name); d <= limit {
+			candidates = append(candidates, candidate{name, d})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := []string{}
+	for i := 0; i < len(candidates) && i < n; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+func (ix *Index) prefixMatches(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	start := sort.SearchStrings(ix.names, prefix)
+	matches := []string{}
+	for i := start; i < len(ix.names) && strings.HasPrefix(ix.names[i], prefix); i++ {
+		matches = append(matches, ix.names[i])
+	}
+	return matches
+}
+
+// Distance returns the Levenshtein edit distance between a and b
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,103 @@
+package nameindex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testIndex() *Index {
+	return New([]Entry{
+		{Name: "pikachu", ID: 25},
+		{Name: "raichu", ID: 26},
+		{Name: "pichu", ID: 172},
+		{Name: "charmander", ID: 4},
+		{Name: "charmeleon", ID: 5},
+		{Name: "charizard", ID: 6},
+		{Name: "bulbasaur", ID: 1},
+	})
+}
+
+func TestResolve(t *testing.T) {
+	ix := testIndex()
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{"pikachu", "pikachu"},
+		{"PIKACHU", "pikachu"},
+		{"25", "pikachu"},
+		{"#6", "charizard"},
+		{"bulb", "bulbasaur"},
+		{"chariz", "charizard"},
+	}
+	for _, c := range cases {
+		got, err := ix.Resolve(c.query)
+		if err != nil {
+			t.Errorf("Resolve(%s): unexpected error: %v", c.query, err)
+			continue
+		}
+		if got != c.expected {
+			t.Errorf("Resolve(%s) = %s, expected %s", c.query, got, c.expected)
+		}
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	ix := testIndex()
+
+	_, err := ix.Resolve("pikachuu")
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) {
+		t.Fatalf("expected LookupError, got %v", err)
+	}
+	if lookupErr.Ambiguous {
+		t.Errorf("expected not found, got ambiguous")
+	}
+	if !reflect.DeepEqual(lookupErr.Suggestions, []string{"pikachu"}) {
+		t.Errorf("expected pikachu suggestion, got %v", lookupErr.Suggestions)
+	}
+	if lookupErr.DidYouMean() != "Did you mean: pikachu?" {
+		t.Errorf("unexpected DidYouMean: %s", lookupErr.DidYouMean())
+	}
+
+	if _, err := ix.Resolve("9999"); err == nil {
+		t.Errorf("expected unknown id to fail")
+	}
+}
+
+func TestResolveAmbiguousPrefix(t *testing.T) {
+	_, err := testIndex().Resolve("charm")
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) || !lookupErr.Ambiguous {
+		t.Fatalf("expected ambiguous LookupError, got %v", err)
+	}
+	if !reflect.DeepEqual(lookupErr.Suggestions, []string{"charmander", "charmeleon"}) {
+		t.Errorf("unexpected suggestions: %v", lookupErr.Suggestions)
+	}
+}
+
+func TestSuggestOrder(t *testing.T) {
+	got := testIndex().Suggest("pichuu", 3)
+	if len(got) == 0 || got[0] != "pichu" {
+		t.Errorf("expected pichu first, got %v", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"pikachu", "pikachuu", 1},
+		{"pastoria-city", "pastoria-city-area", 5},
+	}
+	for _, c := range cases {
+		if got := Distance(c.a, c.b); got != c.expected {
+			t.Errorf("Distance(%s, %s) = %d, expected %d", c.a, c.b, got, c.expected)
+		}
+	}
+}
@@ -11,13 +11,16 @@ type cacheEntry struct {
 }
 
 type Cache struct {
-	entry map[string]cacheEntry
-	mu    sync.Mutex
+	entry    map[string]cacheEntry
+	mu       sync.Mutex
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewCache(interval time.Duration) *Cache {
 	c := &Cache{
 		entry: make(map[string]cacheEntry),
+		done:  make(chan struct{}),
 	}
 	go c.reapLoop(interval)
 	return c
@@ -25,7 +28,13 @@ func NewCache(interval time.Duration) *Cache {
 
 func (c *Cache) reapLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
 		c.mu.Lock()
 		for key := range c.entry {
 			if time.Since(c.entry[key].createdAt) > interval {
@@ -36,6 +45,12 @@ func (c *Cache) reapLoop(interval time.Duration) {
 	}
 }
 
+// Stop ends the background reap loop. It is safe to call more than once and
+// the cache can still be read and written afterwards, entries just no longer expire.
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
+
 func (c *Cache) Add(key string, val []byte) {
 	var newEntry = cacheEntry{}
 	newEntry.createdAt = time.Now()
@@ -163,3 +163,17 @@ func TestMutexRaceCondition(t *testing.T) {
 		return
 	}
 }
+
+func TestStop(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	cache := NewCache(interval)
+	cache.Stop()
+	cache.Stop()
+
+	cache.Add("https://example.com", []byte("testdata"))
+	time.Sleep(interval * 4)
+
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected entries to stay after the reap loop is stopped")
+	}
+}
@@ -0,0 +1,133 @@
+package savefile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrCorrupt is returned when a save file cannot be parsed or its checksum does not match
+var ErrCorrupt = errors.New("save file is corrupt")
+
+// ErrTooNew is returned for save files written by a newer version of the program
+var ErrTooNew = errors.New("save file is from a newer version")
+
+// Migration upgrades the data of a save file by one schema version
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// envelope is what is written to disk around the saved data
+type envelope struct {
+	Version  int             `json:"version"`
+	SavedAt  time.Time       `json:"saved_at"`
+	Checksum string          `json:"checksum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Store reads and writes one save file at a schema version. Migrations are keyed
+// by the version they upgrade from, so migrations[1] turns version 1 data into version 2.
+type Store struct {
+	Path       string
+	version    int
+	migrations map[int]Migration
+}
+
+// New creates a store for the save file at path holding data of the given schema version
+func New(path string, version int, migrations map[int]Migration) *Store {
+	return &Store{Path: path, version: version, migrations: migrations}
+}
+
+// Save writes v as the data of the save file. The file is written next to the
+// old one and renamed over it, so a crash never leaves a half written save.
+func (s *Store) Save(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("Error encoding save data: %w", err)
+	}
+	file, err := json.MarshalIndent(envelope{
+		Version:  s.version,
+		SavedAt:  time.Now().UTC(),
+		Checksum: checksum(data),
+		Data:     data,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error encoding save file: %w", err)
+	}
+
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("Error creating save directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("Error creating save file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(file, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Error writing save file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Error writing save file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Error writing save file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("Error replacing save file: %w", err)
+	}
+	return nil
+}
+
+// Load reads the save file into v, migrating older versions to the current one.
+// A missing file returns an error matching os.ErrNotExist, a damaged one ErrCorrupt.
+func (s *Store) Load(v any) error {
+	file, err := os.ReadFile(s.Path)
+	if err != nil {
+		return fmt.Errorf("Error reading save file: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(file, &env); err != nil || env.Data == nil {
+		return fmt.Errorf("%w: %s is not a save file", ErrCorrupt, s.Path)
+	}
+	if env.Checksum != checksum(env.Data) {
+		return fmt.Errorf("%w: checksum of %s does not match", ErrCorrupt, s.Path)
+	}
+	if env.Version > s.version {
+		return fmt.Errorf("%w: %s has version %d, this program reads up to version %d", ErrTooNew, s.Path, env.Version, s.version)
+	}
+
+	data := env.Data
+	for version := env.Version; version < s.version; version++ {
+		migrate, exists := s.migrations[version]
+		if !exists {
+			return fmt.Errorf("No migration from save version %d", version)
+		}
+		if data, err = migrate(data); err != nil {
+			return fmt.Errorf("Error migrating save from version %d: %w", version, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// checksum hashes the compact form of the data, so indentation does not change it
+func checksum(data json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:])
+}
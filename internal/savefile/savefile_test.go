@@ -0,0 +1,110 @@
+package savefile
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type saveV1 struct {
+	Caught []string `json:"caught"`
+}
+
+type saveV2 struct {
+	Caught map[string]int `json:"caught"`
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedexcli", "save.json")
+	store := New(path, 1, nil)
+
+	if err := store.Load(&saveV1{}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+	if err := store.Save(saveV1{Caught: []string{"magikarp", "gastrodon"}}); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	var loaded saveV1
+	if err := store.Load(&loaded); err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if strings.Join(loaded.Caught, ",") != "magikarp,gastrodon" {
+		t.Errorf("expected the saved data back, got %v", loaded.Caught)
+	}
+
+	//Only the save file is left behind, not the temporary file it was written to
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected a single file in the save directory, got %d", len(entries))
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	store := New(path, 1, nil)
+	if err := store.Save(saveV1{Caught: []string{"magikarp"}}); err != nil {
+		t.Fatal(err)
+	}
+	file, _ := os.ReadFile(path)
+
+	cases := map[string]string{
+		"truncated": string(file[:len(file)/2]),
+		"tampered":  strings.Replace(string(file), "magikarp", "mewtwo", 1),
+		"not json":  "hello",
+		"empty":     "{}",
+	}
+	for name, contents := range cases {
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Load(&saveV1{}); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected ErrCorrupt, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := New(path, 1, nil).Save(saveV1{Caught: []string{"magikarp", "magikarp"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations := map[int]Migration{
+		1: func(data json.RawMessage) (json.RawMessage, error) {
+			var old saveV1
+			if err := json.Unmarshal(data, &old); err != nil {
+				return nil, err
+			}
+			upgraded := saveV2{Caught: map[string]int{}}
+			for _, name := range old.Caught {
+				upgraded.Caught[name]++
+			}
+			return json.Marshal(upgraded)
+		},
+	}
+	var loaded saveV2
+	if err := New(path, 2, migrations).Load(&loaded); err != nil {
+		t.Fatalf("unexpected error migrating: %v", err)
+	}
+	if loaded.Caught["magikarp"] != 2 {
+		t.Errorf("expected the migrated data, got %v", loaded.Caught)
+	}
+
+	if err := New(path, 3, migrations).Load(&loaded); err == nil || !strings.Contains(err.Error(), "No migration from save version 2") {
+		t.Errorf("expected a missing migration error, got %v", err)
+	}
+}
+
+func TestLoadTooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := New(path, 3, nil).Save(saveV1{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(path, 2, nil).Load(&saveV1{}); !errors.Is(err, ErrTooNew) {
+		t.Errorf("expected ErrTooNew, got %v", err)
+	}
+}
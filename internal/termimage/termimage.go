@@ -0,0 +1,157 @@
+package termimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode selects the ANSI escape sequences used for colors
+type ColorMode int
+
+const (
+	// TrueColor uses 24-bit RGB escape sequences
+	TrueColor ColorMode = iota
+	// Color256 maps every pixel onto the xterm 256-color palette
+	Color256
+)
+
+// Pixels with less alpha than this are drawn as the terminal background
+const alphaThreshold = 0x8000
+
+// DetectColorMode reports TrueColor when the terminal advertises 24-bit support through COLORTERM
+func DetectColorMode() ColorMode {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	return Color256
+}
+
+// Render draws img to w using half-block characters, packing two rows of
+// pixels into each line of text. Fully transparent borders are trimmed.
+func Render(w io.Writer, img image.Image, mode ColorMode) error {
+	bounds := opaqueBounds(img)
+	if bounds.Empty() {
+		return nil
+	}
+
+	var sb strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top, topOK := pixel(img, x, y)
+			bottom, bottomOK := color.RGBA{}, false
+			if y+1 < bounds.Max.Y {
+				bottom, bottomOK = pixel(img, x, y+1)
+			}
+
+			switch {
+			case topOK && bottomOK:
+				sb.WriteString(foreground(top, mode))
+				sb.WriteString(background(bottom, mode))
+				sb.WriteString("▀")
+			case topOK:
+				sb.WriteString(foreground(top, mode))
+				sb.WriteString("▀")
+			case bottomOK:
+				sb.WriteString(foreground(bottom, mode))
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+			sb.WriteString("\x1b[0m")
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// pixel returns the color at (x, y) and whether it is opaque enough to draw
+func pixel(img image.Image, x, y int) (color.RGBA, bool) {
+	r, g, b, a := img.At(x, y).RGBA()
+	if a < alphaThreshold {
+		return color.RGBA{}, false
+	}
+	// Undo the alpha premultiplication so semi-transparent edges keep their color
+	return color.RGBA{
+		R: uint8(r * 0xffff / a >> 8),
+		G: uint8(g * 0xffff / a >> 8),
+		B: uint8(b * 0xffff / a >> 8),
+		A: 0xff,
+	}, true
+}
+
+// opaqueBounds returns the smallest rectangle containing every drawable pixel
+func opaqueBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	result := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, ok := pixel(img, x, y); ok {
+				result = result.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return result
+}
+
+func foreground(c color.RGBA, mode ColorMode) string {
+	if mode == TrueColor {
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm", xterm256(c))
+}
+
+func background(c color.RGBA, mode ColorMode) string {
+	if mode == TrueColor {
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("\x1b[48;5;%dm", xterm256(c))
+}
+
+// cubeLevels are the channel intensities of the 6x6x6 xterm color cube
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// xterm256 returns the closest color in the xterm palette, choosing between the
+// color cube (16-231) and the grayscale ramp (232-255)
+func xterm256(c color.RGBA) int {
+	cube := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := cube(c.R), cube(c.G), cube(c.B)
+	cubeIndex := 16 + 36*ri + 6*gi + bi
+	cubeDist := distance(c, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	gray := (int(c.R) + int(c.G) + int(c.B)) / 3
+	grayStep := min(max((gray-8+5)/10, 0), 23)
+	grayLevel := 8 + 10*grayStep
+	grayDist := distance(c, grayLevel, grayLevel, grayLevel)
+
+	if grayDist < cubeDist {
+		return 232 + grayStep
+	}
+	return cubeIndex
+}
+
+func distance(c color.RGBA, r, g, b int) int {
+	dr, dg, db := int(c.R)-r, int(c.G)-g, int(c.B)-b
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package termimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRenderTrueColor(t *testing.T) {
+	// A 4x4 transparent image with a 2x2 red/blue block in the middle
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	img.Set(2, 1, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 2, color.NRGBA{B: 255, A: 255})
+
+	var buf bytes.Buffer
+	if err := Render(&buf, img, TrueColor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀\x1b[0m" +
+		"\x1b[38;2;255;0;0m▀\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected render output: %q", buf.String())
+	}
+}
+
+func TestRenderBottomOnlyAndOddHeight(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(0, 0, color.NRGBA{G: 255, A: 255})
+	img.Set(1, 1, color.NRGBA{G: 255, A: 255})
+	img.Set(0, 2, color.NRGBA{G: 255, A: 255})
+
+	var buf bytes.Buffer
+	if err := Render(&buf, img, TrueColor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines for 3 pixel rows, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "▄") {
+		t.Errorf("expected a lower half block for a bottom-only pixel, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " \x1b[0m") {
+		t.Errorf("expected transparent pixel drawn as a space, got %q", lines[1])
+	}
+}
+
+func TestRenderFullyTransparent(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, image.NewNRGBA(image.Rect(0, 0, 8, 8)), Color256); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for a transparent image, got %q", buf.String())
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	cases := []struct {
+		c        color.RGBA
+		expected int
+	}{
+		{color.RGBA{0, 0, 0, 255}, 16},
+		{color.RGBA{255, 255, 255, 255}, 231},
+		{color.RGBA{255, 0, 0, 255}, 196},
+		{color.RGBA{128, 128, 128, 255}, 244},
+	}
+	for _, c := range cases {
+		if got := xterm256(c.c); got != c.expected {
+			t.Errorf("xterm256(%v) = %d, expected %d", c.c, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pokedexcli/internal/api"
+	"strings"
+)
+
+const defaultLanguage = "en"
+
+// languages are the language codes PokeAPI provides names in
+var languages = []string{"cs", "de", "en", "es", "fr", "it", "ja", "ja-hrkt", "ko", "pt-br", "roomaji", "zh-hans", "zh-hant"}
+
+func validLanguage(code string) bool {
+	for _, l := range languages {
+		if l == code {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchSpeciesByURL loads the species a Pokemon belongs to
+func fetchSpeciesByURL(url string, cfg *config) (api.PokemonSpecies, error) {
+	var species api.PokemonSpecies
+	body, err := api.ApiRequest(url, cfg.pokecache)
+	if err != nil {
+		return species, fmt.Errorf("Error looking up species: %w", err)
+	}
+	if err := json.Unmarshal(body, &species); err != nil {
+		return species, fmt.Errorf("Error unmarshalling JSON: %w", err)
+	}
+	return species, nil
+}
+
+// speciesDisplayName picks the localized name of a species for a Pokemon, keeping
+// the form suffix so that e.g. charizard-mega-x stays distinguishable
+func speciesDisplayName(pokemon string, species api.PokemonSpecies, language string) string {
+	name, ok := api.LocalizedName(species.Names, language)
+	if !ok {
+		return pokemon
+	}
+	if pokemon != species.Name {
+		name += " (" + strings.TrimPrefix(pokemon, species.Name+"-") + ")"
+	}
+	return name
+}
+
+// pokemonDisplayName returns the localized name of a single Pokemon, or its slug if there is none
+func (c *config) pokemonDisplayName(pokemon api.Pokemon) string {
+	if pokemon.Species.URL == "" {
+		return pokemon.Name
+	}
+	species, err := fetchSpeciesByURL(pokemon.Species.URL, c)
+	if err != nil {
+		return pokemon.Name
+	}
+	return speciesDisplayName(pokemon.Name, species, c.language)
+}
+
+// areaDisplayName returns the localized name of an area, or its slug if there is none
+func (c *config) areaDisplayName(area api.Area) string {
+	if name, ok := api.LocalizedName(area.Names, c.language); ok {
+		return name
+	}
+	return area.Name
+}
+
+// localizePokemonNames translates a list of Pokemon slugs through their species,
+// keeping the slug of any that cannot be looked up. English slugs already read as
+// English names, so nothing is fetched for English
+func (c *config) localizePokemonNames(names []string) []string {
+	if c.language == defaultLanguage {
+		return names
+	}
+
+	urls := make([]string, len(names))
+	for i, name := range names {
+		urls[i] = "https://pokeapi.co/api/v2/pokemon-species/" + name
+	}
+	localized := append([]string{}, names...)
+	results, _ := api.FetchMany(urls, c.pokecache, c.concurrency)
+	for i, result := range results {
+		var species api.PokemonSpecies
+		if result.Err == nil && json.Unmarshal(result.Body, &species) == nil {
+			localized[i] = speciesDisplayName(names[i], species, c.language)
+		}
+	}
+	return localized
+}
+
+// localizeSpeciesNames translates a list of species slugs, again only outside English
+func (c *config) localizeSpeciesNames(names, urls []string) []string {
+	if c.language == defaultLanguage {
+		return names
+	}
+
+	localized := append([]string{}, names...)
+	results, _ := api.FetchMany(urls, c.pokecache, c.concurrency)
+	for i, result := range results {
+		var species api.PokemonSpecies
+		if result.Err == nil && json.Unmarshal(result.Body, &species) == nil {
+			if name, ok := api.LocalizedName(species.Names, c.language); ok {
+				localized[i] = name
+			}
+		}
+	}
+	return localized
+}
+
+func commandSet(cfg *config, a args) (result, error) {
+	fields := a.positional
+	if len(fields) == 0 {
+		return settingsResult{Language: cfg.language, Output: cfg.output, Version: cfg.versionSetting()}, nil
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("Usage: set <setting> <value>")
+	}
+
+	fields[0], fields[1] = strings.ToLower(fields[0]), strings.ToLower(fields[1])
+	switch fields[0] {
+	case "language":
+		if !validLanguage(fields[1]) {
+			return nil, fmt.Errorf("Unknown language '%s'. Available languages: %s", fields[1], strings.Join(languages, ", "))
+		}
+		cfg.language = fields[1]
+	case "output":
+		if err := validOutput(fields[1]); err != nil {
+			return nil, err
+		}
+		cfg.output, cfg.savedOutput = fields[1], fields[1]
+	case "version":
+		if fields[1] != anyVersion && !validVersion(fields[1]) {
+			return nil, fmt.Errorf("Unknown version '%s'. Available versions: %s, %s", fields[1], anyVersion, strings.Join(versions, ", "))
+		}
+		if fields[1] == anyVersion {
+			cfg.version = ""
+		} else {
+			cfg.version = fields[1]
+		}
+	default:
+		return nil, fmt.Errorf("Unknown setting '%s'", fields[0])
+	}
+
+	return messageResult{Message: fmt.Sprintf("%s set to %s", fields[0], fields[1])}, nil
+}
+
+// settingsResult is the current value of every setting
+type settingsResult struct {
+	Language string `json:"language"`
+	Output   string `json:"output"`
+	Version  string `json:"version"`
+}
+
+func (s settingsResult) text(w io.Writer) error {
+	fmt.Fprintf(w, "language: %s\n", s.Language)
+	fmt.Fprintf(w, "output: %s\n", s.Output)
+	fmt.Fprintf(w, "version: %s\n", s.Version)
+	return nil
+}
+
+func (s settingsResult) table() ([]string, [][]string) {
+	return []string{"setting", "value"}, [][]string{{"language", s.Language}, {"output", s.Output}, {"version", s.Version}}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandSetLanguage(t *testing.T) {
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("set language fr") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "language set to fr\n" || commands.config.language != "fr" {
+		t.Errorf("expected language to be set to fr, got %s (%s)", commands.config.language, out)
+	}
+
+	captureOutput(commands, func() { err = commands.run("set language klingon") })
+	if err == nil || !strings.Contains(err.Error(), "Unknown language 'klingon'") {
+		t.Errorf("expected unknown language error, got: %v", err)
+	}
+	if commands.config.language != "fr" {
+		t.Errorf("expected invalid language to be ignored")
+	}
+}
+
+func TestCommandExploreLocalized(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.language = "fr"
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("explore pastoria-city-area") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Exploring Voilaroc...\n" +
+		"Found Pokemon:\n" +
+		" - Tentacool\n" +
+		" - Tentacruel\n" +
+		" - Magicarpe\n" +
+		" - Léviator\n" +
+		" - Sancoki\n" +
+		" - Tritosor\n"
+	if out != expected {
+		t.Errorf("unexpected explore output:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestCommandInspectLocalized(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	mon, err := fetchPokemon("magikarp", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.pokedex["magikarp"] = newDexPokemon(mon)
+
+	out := captureOutput(commands, func() { err = commands.run("inspect magikarp") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "Name: Magikarp\nDescription: It is virtually worthless in terms of both power and speed. It is the most weak and pathetic POKéMON in the world.\n") {
+		t.Errorf("expected latest English flavor text, got:\n%s", out)
+	}
+
+	cfg.language = "fr"
+	out = captureOutput(commands, func() { err = commands.run("inspect magikarp") })
+	if !strings.HasPrefix(out, "Name: Magicarpe\nDescription: Un Pokémon pathétique. Il se contente de barboter.\n") {
+		t.Errorf("expected French name and flavor text, got:\n%s", out)
+	}
+
+	// Without a Korean name or flavor text everything falls back to the slug
+	cfg.language = "ko"
+	out = captureOutput(commands, func() { err = commands.run("inspect magikarp") })
+	if !strings.HasPrefix(out, "Name: magikarp\nHeight: 9\n") {
+		t.Errorf("expected slug fallback, got:\n%s", out)
+	}
+}
+
+func TestSpeciesDisplayNameKeepsForm(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	cfg := commands.config
+	cfg.language = "fr"
+
+	mon, err := fetchPokemon("charizard-mega-x", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.pokemonDisplayName(mon); got != "Dracaufeu (mega-x)" {
+		t.Errorf("expected Dracaufeu (mega-x), got %s", got)
+	}
+}
+
+func TestLocalizedDetailsTableAndForms(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.language = "fr"
+
+	cases := map[string]string{
+		"explore pastoria-city-area --details": "Exploring Voilaroc...\n" +
+			"Found Pokemon:\n" +
+			" - Tentacool [water/poison]\n" +
+			"     hp: 40  attack: 40  defense: 35  special-attack: 50  special-defense: 100  speed: 70\n" +
+			" - Tentacruel [water/poison]\n" +
+			"     hp: 80  attack: 70  defense: 65  special-attack: 80  special-defense: 120  speed: 100\n" +
+			" - Magicarpe [water]\n" +
+			"     hp: 20  attack: 10  defense: 55  special-attack: 15  special-defense: 20  speed: 80\n" +
+			" - Léviator [water/flying]\n" +
+			"     hp: 95  attack: 125  defense: 79  special-attack: 60  special-defense: 100  speed: 81\n" +
+			" - Sancoki [water]\n" +
+			"     hp: 76  attack: 48  defense: 48  special-attack: 57  special-defense: 62  speed: 34\n" +
+			" - Tritosor [water/ground]\n" +
+			"     hp: 111  attack: 83  defense: 68  special-attack: 92  special-defense: 82  speed: 39\n",
+		"explore pastoria-city-area --table --version platinum --method good-rod": "Exploring Voilaroc...\n" +
+			"POKEMON    VERSION   METHOD    LEVELS  CHANCE\n" +
+			"Magicarpe  platinum  good-rod  10-25   55%\n" +
+			"Léviator   platinum  good-rod  10-25   5%\n",
+		"forms charizard": "Forms of Dracaufeu:\n" +
+			" - Dracaufeu [default]\n" +
+			" - Dracaufeu (mega-x) [mega, battle only]\n" +
+			" - Dracaufeu (mega-y) [mega, battle only]\n",
+	}
+	for line, expected := range cases {
+		var err error
+		out := captureOutput(commands, func() { err = commands.run(line) })
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", line, err)
+			continue
+		}
+		if out != expected {
+			t.Errorf("%s: unexpected output:\n%s\nexpected:\n%s", line, out, expected)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxMacroDepth stops macros that call themselves from running forever
+const maxMacroDepth = 16
+
+// macroArg matches $1 to $9 and $@ in a macro line
+var macroArg = regexp.MustCompile(`\$([1-9@])`)
+
+// userConfig is the part of the config kept in the user config file
+type userConfig struct {
+	Aliases map[string]string   `json:"aliases"`
+	Macros  map[string][]string `json:"macros"`
+}
+
+// macroRecording is a macro being recorded, line by line
+type macroRecording struct {
+	name  string
+	lines []string
+}
+
+// defaultUserConfigFile is where aliases and macros are kept unless POKEDEX_CONFIG says otherwise
+func defaultUserConfigFile() string {
+	if path := os.Getenv("POKEDEX_CONFIG"); path != "" {
+		return path
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "pokedexcli", "config.json")
+	}
+	return ".pokedex_config.json"
+}
+
+// loadUserConfig reads aliases and macros from path. A missing file is not an
+// error, and later changes are saved back to path.
+func (c *config) loadUserConfig(path string) error {
+	c.userConfigPath = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error reading user config: %w", err)
+	}
+
+	var user userConfig
+	if err := json.Unmarshal(data, &user); err != nil {
+		return fmt.Errorf("Error parsing user config %s: %w", path, err)
+	}
+	for name, command := range user.Aliases {
+		c.userAliases[name] = command
+	}
+	for name, lines := range user.Macros {
+		c.macros[name] = lines
+	}
+	return nil
+}
+
+// saveUserConfig writes aliases and macros back to the file they were loaded from
+func (c *config) saveUserConfig() error {
+	if c.userConfigPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(userConfig{Aliases: c.userAliases, Macros: c.macros}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error encoding user config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.userConfigPath), 0700); err != nil {
+		return fmt.Errorf("Error creating config directory: %w", err)
+	}
+	if err := os.WriteFile(c.userConfigPath, data, 0600); err != nil {
+		return fmt.Errorf("Error writing user config: %w", err)
+	}
+	return nil
+}
+
+// checkUserName makes sure an alias or macro would not hide a command or each other
+func (r *commandRegistry) checkUserName(name string) error {
+	if _, exists := r.lookup(name); exists {
+		return fmt.Errorf("'%s' is already a command", name)
+	}
+	if strings.ContainsAny(name, " \t$") || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("'%s' is not a valid name", name)
+	}
+	return nil
+}
+
+func commandAlias(r *commandRegistry, a args) (result, error) {
+	cfg := r.config
+	if len(a.positional) == 0 {
+		return newAliasList(cfg.userAliases), nil
+	}
+	if len(a.positional) == 1 {
+		return nil, fmt.Errorf("Missing command. Usage: %s", r.commands["alias"].usage)
+	}
+
+	name := strings.ToLower(a.arg(0))
+	if err := r.checkUserName(name); err != nil {
+		return nil, err
+	}
+	if _, exists := cfg.macros[name]; exists {
+		return nil, fmt.Errorf("'%s' is already a macro", name)
+	}
+
+	command := strings.Join(a.positional[1:], " ")
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("Missing command. Usage: %s", r.commands["alias"].usage)
+	}
+	cfg.userAliases[name] = command
+	if err := cfg.saveUserConfig(); err != nil {
+		return nil, err
+	}
+	return messageResult{Message: fmt.Sprintf("%s is now an alias for %s", name, command)}, nil
+}
+
+func commandUnalias(cfg *config, a args) (result, error) {
+	name := strings.ToLower(a.arg(0))
+	if _, exists := cfg.userAliases[name]; !exists {
+		return nil, fmt.Errorf("No alias named '%s'", name)
+	}
+
+	delete(cfg.userAliases, name)
+	if err := cfg.saveUserConfig(); err != nil {
+		return nil, err
+	}
+	return messageResult{Message: fmt.Sprintf("Removed alias %s", name)}, nil
+}
+
+// aliasList is every user defined alias
+type aliasList struct {
+	Aliases []aliasEntry `json:"aliases"`
+}
+
+type aliasEntry struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+func newAliasList(aliases map[string]string) aliasList {
+	list := aliasList{Aliases: []aliasEntry{}}
+	for _, name := range sortedKeys(aliases) {
+		list.Aliases = append(list.Aliases, aliasEntry{Name: name, Command: aliases[name]})
+	}
+	return list
+}
+
+func (l aliasList) text(w io.Writer) error {
+	if len(l.Aliases) == 0 {
+		fmt.Fprintln(w, "No aliases defined. Add one with alias <name> <command>")
+		return nil
+	}
+	for _, alias := range l.Aliases {
+		fmt.Fprintf(w, "%s: %s\n", alias.Name, alias.Command)
+	}
+	return nil
+}
+
+func (l aliasList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Aliases))
+	for i, alias := range l.Aliases {
+		rows[i] = []string{alias.Name, alias.Command}
+	}
+	return []string{"name", "command"}, rows
+}
+
+func commandMacro(r *commandRegistry, a args) (result, error) {
+	cfg := r.config
+	action, name := strings.ToLower(a.arg(0)), strings.ToLower(a.arg(1))
+
+	switch action {
+	case "", "list":
+		return newMacroList(cfg.macros), nil
+	case "record":
+		if name == "" {
+			return nil, fmt.Errorf("Missing macro name. Usage: macro record <name>")
+		}
+		if cfg.recording != nil {
+			return nil, fmt.Errorf("Already recording macro %s. Finish it with macro end", cfg.recording.name)
+		}
+		if err := r.checkUserName(name); err != nil {
+			return nil, err
+		}
+		if _, exists := cfg.userAliases[name]; exists {
+			return nil, fmt.Errorf("'%s' is already an alias", name)
+		}
+		cfg.recording = &macroRecording{name: name}
+		return messageResult{Message: fmt.Sprintf("Recording macro %s. Enter commands using $1, $2, ... for arguments, then macro end to save it", name)}, nil
+	case "end":
+		rec := cfg.recording
+		if rec == nil {
+			return nil, fmt.Errorf("Not recording a macro. Start one with macro record <name>")
+		}
+		cfg.recording = nil
+		if len(rec.lines) == 0 {
+			return messageResult{Message: fmt.Sprintf("Macro %s has no commands and was not saved", rec.name)}, nil
+		}
+		cfg.macros[rec.name] = rec.lines
+		if err := cfg.saveUserConfig(); err != nil {
+			return nil, err
+		}
+		return messageResult{Message: fmt.Sprintf("Saved macro %s with %d commands", rec.name, len(rec.lines))}, nil
+	case "delete":
+		if _, exists := cfg.macros[name]; !exists {
+			return nil, fmt.Errorf("No macro named '%s'", name)
+		}
+		delete(cfg.macros, name)
+		if err := cfg.saveUserConfig(); err != nil {
+			return nil, err
+		}
+		return messageResult{Message: fmt.Sprintf("Deleted macro %s", name)}, nil
+	}
+	return nil, fmt.Errorf("Unknown macro action '%s'. Usage: %s", action, r.commands["macro"].usage)
+}
+
+// macroList is every saved macro and the commands it runs
+type macroList struct {
+	Macros []macroEntry `json:"macros"`
+}
+
+type macroEntry struct {
+	Name     string   `json:"name"`
+	Commands []string `json:"commands"`
+}
+
+func newMacroList(macros map[string][]string) macroList {
+	list := macroList{Macros: []macroEntry{}}
+	for _, name := range sortedKeys(macros) {
+		list.Macros = append(list.Macros, macroEntry{Name: name, Commands: macros[name]})
+	}
+	return list
+}
+
+func (l macroList) text(w io.Writer) error {
+	if len(l.Macros) == 0 {
+		fmt.Fprintln(w, "No macros defined. Record one with macro record <name>")
+		return nil
+	}
+	for _, macro := range l.Macros {
+		fmt.Fprintf(w, "%s:\n", macro.Name)
+		for _, line := range macro.Commands {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	return nil
+}
+
+func (l macroList) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, macro := range l.Macros {
+		for i, line := range macro.Commands {
+			rows = append(rows, []string{macro.Name, strconv.Itoa(i + 1), line})
+		}
+	}
+	return []string{"name", "step", "command"}, rows
+}
+
+// expandAlias replaces a user alias at the start of a command with what it stands
+// for, which may be a pipeline. Arguments after the alias go to its last command.
+func (r *commandRegistry) expandAlias(tokens []string) ([][]string, error) {
+	name := strings.ToLower(tokens[0])
+	expansion, exists := r.config.userAliases[name]
+	if !exists {
+		return [][]string{tokens}, nil
+	}
+
+	stages, err := tokenizePipeline(expansion)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding alias %s: %w", name, err)
+	}
+	for _, stage := range stages {
+		if len(stage) == 0 {
+			return nil, fmt.Errorf("Alias %s has an empty command", name)
+		}
+	}
+	last := len(stages) - 1
+	stages[last] = append(stages[last], tokens[1:]...)
+	return stages, nil
+}
+
+// runMacro runs each line of a macro with $1 to $9 replaced by the arguments and
+// $@ by all of them, stopping at the first line that fails
+func (r *commandRegistry) runMacro(name string, lines []string, macroArgs []string) error {
+	needed := 0
+	for _, line := range lines {
+		for _, match := range macroArg.FindAllStringSubmatch(line, -1) {
+			if n, err := strconv.Atoi(match[1]); err == nil {
+				needed = max(needed, n)
+			}
+		}
+	}
+	if len(macroArgs) < needed {
+		return fmt.Errorf("Macro %s needs %d arguments, got %d", name, needed, len(macroArgs))
+	}
+
+	if r.macroDepth >= maxMacroDepth {
+		return fmt.Errorf("Macro %s nests too deeply", name)
+	}
+	r.macroDepth++
+	defer func() { r.macroDepth-- }()
+
+	quoted := make([]string, len(macroArgs))
+	for i, arg := range macroArgs {
+		quoted[i] = quoteArg(arg)
+	}
+	for i, line := range lines {
+		expanded := macroArg.ReplaceAllStringFunc(line, func(match string) string {
+			if match == "$@" {
+				return strings.Join(quoted, " ")
+			}
+			n, _ := strconv.Atoi(match[1:])
+			return quoted[n-1]
+		})
+		if err := r.run(expanded); err != nil {
+			if errors.Is(err, errExit) || errors.Is(err, errUnknownCommand) {
+				return err
+			}
+			return fmt.Errorf("Macro %s failed at step %d (%s): %w", name, i+1, expanded, err)
+		}
+	}
+	return nil
+}
+
+// quoteArg quotes an argument so the tokenizer reads it back as a single word
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\|") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
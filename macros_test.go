@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedexcli", "config.json")
+	commands := createRegistry()
+	if err := commands.config.loadUserConfig(path); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{`alias lang "set language"`, "LANG fr", "alias"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: %s", line, err)
+			}
+		}
+	})
+	expected := "lang is now an alias for set language\nlanguage set to fr\nlang: set language\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+
+	for _, line := range []string{"alias catch inspect", "alias dex inspect", "alias lang"} {
+		if err := commands.run(line); err == nil {
+			t.Errorf("%s: expected an error", line)
+		}
+	}
+
+	//Aliases are kept in the user config file for the next session
+	next := createRegistry()
+	if err := next.config.loadUserConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if next.config.userAliases["lang"] != "set language" {
+		t.Fatalf("expected the alias to be saved, got %v", next.config.userAliases)
+	}
+	captureOutput(next, func() {
+		if err := next.run("unalias lang"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := next.run("lang de"); !errors.Is(err, errUnknownCommand) {
+		t.Errorf("expected the removed alias to be unknown, got %v", err)
+	}
+}
+
+func TestMacro(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	commands := createRegistry()
+	if err := commands.config.loadUserConfig(path); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"macro record both", "set language $1", "set output $2", "macro end"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: %s", line, err)
+			}
+		}
+	})
+	if commands.config.language != defaultLanguage {
+		t.Errorf("expected recorded lines not to run, language is %s", commands.config.language)
+	}
+	if !strings.HasSuffix(out, "Saved macro both with 2 commands\n") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = captureOutput(commands, func() {
+		if err := commands.run("both ja json"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if commands.config.language != "ja" || commands.config.output != "json" {
+		t.Errorf("expected the macro to set language and output, got %s and %s", commands.config.language, commands.config.output)
+	}
+	if !strings.HasPrefix(out, "language set to ja\n{") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if err := commands.run("both fr"); err == nil || err.Error() != "Macro both needs 2 arguments, got 1" {
+		t.Errorf("expected a missing argument error, got %v", err)
+	}
+	var err error
+	captureOutput(commands, func() { err = commands.run("both fr yaml") })
+	if err == nil || !strings.HasPrefix(err.Error(), "Macro both failed at step 2 (set output yaml): Unknown output format") {
+		t.Errorf("expected the failing step to be reported, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"set output $2"`) {
+		t.Errorf("expected the macro to be saved, got %q (%v)", data, err)
+	}
+}
+
+func TestMacroRecursion(t *testing.T) {
+	commands := createRegistry()
+	commands.config.macros["loop"] = []string{"loop"}
+
+	if err := commands.run("loop"); err == nil || !strings.Contains(err.Error(), "nests too deeply") {
+		t.Errorf("expected recursion to be stopped, got %v", err)
+	}
+	if commands.macroDepth != 0 {
+		t.Errorf("expected macro depth to unwind, got %d", commands.macroDepth)
+	}
+}
+
+func TestMacroCompletion(t *testing.T) {
+	commands := createRegistry()
+	commands.config.userAliases["c"] = "catch"
+	commands.config.macros["hunt"] = []string{"explore $1"}
+	commands.config.encounter = &wildEncounter{Pokemon: "magikarp"}
+
+	names := commands.namesAndAliases()
+	if !slices.Contains(names, "c") || !slices.Contains(names, "hunt") {
+		t.Errorf("expected aliases and macros in %v", names)
+	}
+	if actual := commands.complete("c "); !slices.Equal(actual, []string{"magikarp"}) {
+		t.Errorf("expected completion through the alias, got %v", actual)
+	}
+	if actual := commands.complete("macro delete "); !slices.Equal(actual, []string{"hunt"}) {
+		t.Errorf("expected macro names, got %v", actual)
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, arg := range []string{"magikarp", "mr mime", `say "hi"`, ""} {
+		tokens, err := tokenize("catch " + quoteArg(arg))
+		if err != nil || len(tokens) != 2 || tokens[1] != arg {
+			t.Errorf("%q: expected it to tokenize back, got %q (%v)", arg, tokens, err)
+		}
+	}
+}
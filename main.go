@@ -1,52 +1,121 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"pokedexcli/internal/lineedit"
 )
 
 func main() {
-	//Create map for all possible commands
-	commands := createCommandMap()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	//Initialize input buffer
-	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Print("Pokedex > ")
+// run picks the mode from the arguments and returns the exit code: 0 when every
+// command succeeded, 1 when one failed and 2 for invalid arguments
+func run(argv []string, stdin *os.File, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pokedexcli", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	command := flags.String("c", "", "run a single `command` and exit")
+	stopOnError := flags.Bool("stop-on-error", false, "stop a script at the first command that fails")
+	output := flags.String("output", defaultOutput, "render results as `format`: text, json or csv")
+	profileFlag := flags.String("profile", "", "use the trainer profile `name` (default $POKEDEX_PROFILE or default)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: pokedexcli [-c command] [-output format] [-profile name] [-stop-on-error] [script]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(argv); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 || (flags.NArg() == 1 && *command != "") {
+		flags.Usage()
+		return 2
+	}
+	if err := validOutput(*output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	profile, err := startupProfile(*profileFlag)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
 
-	//Begin accepting input
-	for scanner.Scan() {
-		line := scanner.Text()
+	//Create the registry of all available commands
+	commands := createRegistry()
+	commands.config.out, commands.config.errOut = stdout, stderr
+	if err := commands.config.loadUserConfig(defaultUserConfigFile()); err != nil {
+		fmt.Fprintf(stderr, "Could not load aliases and macros: %s\n", err)
+	}
 
-		//Clean provided input by separating by whitespace and forcing lowecase
-		words := cleanInput(line)
+	//Continue from the profile's last session and save it again however this one ends
+	if err := migrateLegacySave(); err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+	if err := commands.config.useProfile(profile); err != nil {
+		fmt.Fprintf(stderr, "Could not load profile %s: %s\n", profile, err)
+	}
+	commands.onShutdown(commands.config.autosave)
 
-		//Ask for input if none was provided
-		if len(words) == 0 {
-			fmt.Println("Please enter a command")
-			fmt.Print("Pokedex > ")
-			continue
+	//-output changes the format for this run only, not the one saved with the profile
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "output" {
+			commands.config.output = *output
 		}
+	})
+
+	code := runMode(commands, *command, flags.Arg(0), stdin, stdout, stderr, *stopOnError)
 
-		//Check if the command exists in the command map and execute if so
-		command, exists := commands[words[0]]
-		if exists {
-			if len(words) > 1 {
-				err := command.callback(words[1])
-				if err != nil {
-					fmt.Printf("An error has occurred: %s\n", err)
-				}
-			} else {
-				err := command.callback("")
-				if err != nil {
-					fmt.Printf("An error has occurred: %s\n", err)
-				}
-			}
-		} else {
-			fmt.Println("Unknown command")
+	//Shutdown hooks run however the session ended, whether by exit, end of input or the last command
+	if err := commands.shutdown(); err != nil {
+		fmt.Fprintf(stderr, "Error shutting down: %s\n", err)
+		return max(code, 1)
+	}
+	return code
+}
+
+// runMode runs a single command, a script file, piped input or the interactive REPL
+func runMode(commands *commandRegistry, command, script string, stdin *os.File, stdout, stderr io.Writer, stopOnError bool) int {
+	switch {
+	case command != "":
+		if err := commands.run(command); err != nil && !errors.Is(err, errExit) {
+			fmt.Fprintln(stderr, errorMessage(err))
+			return 1
 		}
+		return 0
+	case script != "":
+		f, err := os.Open(script)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error opening script: %s\n", err)
+			return 1
+		}
+		defer f.Close()
+		return exitCode(runScript(commands, script, f, stderr, stopOnError))
+	case !lineedit.IsTerminal(stdin):
+		//Piped input runs like a script, without prompts
+		return exitCode(runScript(commands, "stdin", stdin, stderr, stopOnError))
+	}
+
+	//Set up line editing with history kept across sessions
+	editor := lineedit.New(stdin, stdout)
+	history, err := lineedit.LoadHistory(defaultHistoryFile(), 1000)
+	if err != nil {
+		fmt.Fprintf(stderr, "Could not load history: %s\n", err)
+	}
+	editor.History = history
+
+	if err := interactive(commands, editor, stderr); err != nil {
+		fmt.Fprintf(stderr, "Error shutting down: %s\n", err)
+		return 1
+	}
+	return 0
+}
 
-		//Print line to start the loop over
-		fmt.Print("Pokedex > ")
+func exitCode(ok bool) int {
+	if ok {
+		return 0
 	}
+	return 1
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/nameindex"
+	"pokedexcli/internal/pokecache"
+	"strings"
+)
+
+const (
+	pokemonListURL = "https://pokeapi.co/api/v2/pokemon?limit=100000"
+	areaListURL    = "https://pokeapi.co/api/v2/location-area?limit=100000"
+)
+
+// loadNameIndex builds an index from every entry of a PokeAPI list endpoint
+func loadNameIndex(url string, cache *pokecache.Cache) (*nameindex.Index, error) {
+	body, err := api.ApiRequest(url, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	var list api.ResourceList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling JSON: %w", err)
+	}
+
+	entries := make([]nameindex.Entry, len(list.Results))
+	for i, result := range list.Results {
+		entries[i] = nameindex.Entry{Name: result.Name, ID: result.ID()}
+	}
+	return nameindex.New(entries), nil
+}
+
+// resolvePokemon turns a name, national dex number or unique prefix into a Pokemon name.
+// If the index cannot be loaded the query is passed through unchanged.
+func (c *config) resolvePokemon(query string) (string, error) {
+	query = strings.ToLower(query)
+	if c.pokemonNames == nil {
+		index, err := loadNameIndex(pokemonListURL, c.pokecache)
+		if err != nil {
+			return query, nil
+		}
+		c.pokemonNames = index
+	}
+
+	name, err := c.pokemonNames.Resolve(query)
+	if err != nil {
+		return "", notFoundError("Pokemon", query, err)
+	}
+	return name, nil
+}
+
+// resolveArea turns a location area name, id or unique prefix into a location area name
+func (c *config) resolveArea(query string) (string, error) {
+	query = strings.ToLower(query)
+	if c.areaNames == nil {
+		index, err := loadNameIndex(areaListURL, c.pokecache)
+		if err != nil {
+			return query, nil
+		}
+		c.areaNames = index
+	}
+
+	name, err := c.areaNames.Resolve(query)
+	if err != nil {
+		return "", notFoundError("Area", query, err)
+	}
+	return name, nil
+}
+
+// notFoundError builds the user facing message for a name that could not be found,
+// including suggestions when the lookup produced any
+func notFoundError(kind, query string, err error) error {
+	var lookupErr *nameindex.LookupError
+	if errors.As(err, &lookupErr) && len(lookupErr.Suggestions) > 0 {
+		if lookupErr.Ambiguous {
+			return fmt.Errorf("%s '%s' matches more than one name. %s", kind, query, lookupErr.DidYouMean())
+		}
+		return fmt.Errorf("%s '%s' does not exist. %s", kind, query, lookupErr.DidYouMean())
+	}
+	return fmt.Errorf("%s '%s' does not exist. Please check spelling and try again", kind, query)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandCatchSuggestsName(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("catch pikachuu") })
+	if err == nil || err.Error() != "Pokemon 'pikachuu' does not exist. Did you mean: pikachu?" {
+		t.Errorf("expected suggestion error, got: %v", err)
+	}
+	if out != "" {
+		t.Errorf("expected no Pokeball to be thrown, got:\n%s", out)
+	}
+}
+
+func TestCommandExploreResolvesPrefix(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("explore pastoria-city") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "Exploring Pastoria City...\n") {
+		t.Errorf("expected prefix to resolve to pastoria-city-area, got:\n%s", out)
+	}
+}
+
+func TestCommandExploreSuggestsArea(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	var err error
+	captureOutput(commands, func() { err = commands.run("explore pastoira-city-area") })
+	if err == nil || !strings.Contains(err.Error(), "Did you mean: pastoria-city-area") {
+		t.Errorf("expected area suggestion, got: %v", err)
+	}
+}
+
+func TestResolvePokemonByDexNumber(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	name, err := commands.config.resolvePokemon("129")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "magikarp" {
+		t.Errorf("expected dex number 129 to resolve to magikarp, got %s", name)
+	}
+
+	// charizard is a full name, so it must not be treated as an ambiguous prefix of its megas
+	name, err = commands.config.resolvePokemon("charizard")
+	if err != nil || name != "charizard" {
+		t.Errorf("expected charizard, got %s, %v", name, err)
+	}
+
+	_, err = commands.config.resolvePokemon("char")
+	if err == nil || !strings.Contains(err.Error(), "matches more than one name") {
+		t.Errorf("expected ambiguous prefix error, got: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultOutput is the format results are rendered in unless --output or set output says otherwise
+const defaultOutput = "text"
+
+// result is the structured output of a command. Commands build results and a
+// formatter decides how they are shown, so every command supports every format.
+type result interface {
+	// text writes the result as prose for people
+	text(w io.Writer) error
+	// table returns a header and rows for tabular formats
+	table() ([]string, [][]string)
+}
+
+// formatter renders a command result
+type formatter interface {
+	format(w io.Writer, r result) error
+}
+
+// formatters holds every output format by name
+var formatters = map[string]formatter{
+	"text": textFormatter{},
+	"json": jsonFormatter{},
+	"csv":  csvFormatter{},
+}
+
+// outputFormats returns the names of all output formats in alphabetical order
+func outputFormats() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validOutput(name string) error {
+	if _, exists := formatters[name]; !exists {
+		return fmt.Errorf("Unknown output format '%s'. Available formats: %s", name, strings.Join(outputFormats(), ", "))
+	}
+	return nil
+}
+
+// render writes a result to the configured output in the configured format
+func (c *config) render(r result) error {
+	f, exists := formatters[c.output]
+	if !exists {
+		f = formatters[defaultOutput]
+	}
+	return f.format(c.out, r)
+}
+
+type textFormatter struct{}
+
+func (textFormatter) format(w io.Writer, r result) error {
+	return r.text(w)
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) format(w io.Writer, r result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("Error encoding JSON: %w", err)
+	}
+	return nil
+}
+
+type csvFormatter struct{}
+
+func (csvFormatter) format(w io.Writer, r result) error {
+	header, rows := r.table()
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("Error writing CSV: %w", err)
+	}
+	return nil
+}
+
+// messageResult is a single line of feedback such as a confirmation
+type messageResult struct {
+	Message string `json:"message"`
+}
+
+func (m messageResult) text(w io.Writer) error {
+	_, err := fmt.Fprintln(w, m.Message)
+	return err
+}
+
+func (m messageResult) table() ([]string, [][]string) {
+	return []string{"message"}, [][]string{{m.Message}}
+}
+
+// statValue is one base stat of a Pokemon
+type statValue struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// statNames are the base stats every Pokemon has, in the order PokeAPI lists them
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// statColumns returns the value of each of statNames for a CSV row
+func statColumns(stats []statValue) []string {
+	columns := make([]string, len(statNames))
+	for i, name := range statNames {
+		for _, stat := range stats {
+			if stat.Name == name {
+				columns[i] = strconv.Itoa(stat.Value)
+			}
+		}
+	}
+	return columns
+}
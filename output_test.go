@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputJSON(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.output = "json"
+
+	out := captureOutput(commands, func() {
+		if err := commands.run("explore pastoria-city-area"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	var explored struct {
+		Area struct {
+			Name        string `json:"name"`
+			DisplayName string `json:"display_name"`
+		} `json:"area"`
+		Pokemon []struct {
+			Name string `json:"name"`
+		} `json:"pokemon"`
+	}
+	if err := json.Unmarshal([]byte(out), &explored); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, out)
+	}
+	if explored.Area.Name != "pastoria-city-area" || explored.Area.DisplayName != "Pastoria City" {
+		t.Errorf("unexpected area %+v", explored.Area)
+	}
+	if len(explored.Pokemon) != 6 || explored.Pokemon[2].Name != "magikarp" {
+		t.Errorf("unexpected pokemon %+v", explored.Pokemon)
+	}
+}
+
+func TestOutputCSV(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.output = "csv"
+
+	out := captureOutput(commands, func() {
+		if err := commands.run("explore pastoria-city-area --table --version platinum --method good-rod"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "area,pokemon,display_name,version,method,min_level,max_level,chance" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if len(lines) < 2 || lines[1] != "pastoria-city-area,magikarp,magikarp,platinum,good-rod,10,25,55" {
+		t.Errorf("unexpected rows:\n%s", out)
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	commands := createRegistry()
+
+	out := captureOutput(commands, func() {
+		commands.run("set output json")
+		commands.run("set")
+	})
+	expected := `{
+  "message": "output set to json"
+}
+{
+  "language": "en",
+  "output": "json",
+  "version": "any"
+}
+`
+	if out != expected {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	if err := commands.run("set output yaml"); err == nil || !strings.Contains(err.Error(), "Available formats: csv, json, text") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestRunOutputFlag(t *testing.T) {
+	t.Setenv("POKEDEX_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run([]string{"-output", "csv", "-c", "set"}, os.Stdin, stdout, stderr)
+	if code != 0 || stdout.String() != "setting,value\nlanguage,en\noutput,csv\nversion,any\n" {
+		t.Errorf("unexpected result %d:\n%s", code, stdout.String())
+	}
+
+	code = run([]string{"-output", "yaml", "-c", "set"}, os.Stdin, stdout, stderr)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for an unknown format, got %d", code)
+	}
+}
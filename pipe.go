@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"pokedexcli/internal/api"
+	"slices"
+	"strings"
+)
+
+// Kinds of item passed between piped commands
+const (
+	itemPokemon = "pokemon"
+	itemArea    = "area"
+)
+
+// item is one element of the stream passed from one command of a pipeline to the next
+type item struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// itemSource is a result that can be piped into another command
+type itemSource interface {
+	items() []item
+}
+
+// runPipeline runs each command of a pipeline in turn. Every command but the last
+// must produce items, which the next one takes either one at a time as its first
+// argument or, for stream commands such as filter, all at once. Only the output of
+// the last command is shown.
+func (r *commandRegistry) runPipeline(stages [][]string) error {
+	//Check every command can take part before running any of them
+	cmds := make([]*cliCommand, len(stages))
+	for i, stage := range stages {
+		word := strings.ToLower(stage[0])
+		cmd, exists := r.lookup(word)
+		if !exists {
+			if _, isMacro := r.config.macros[word]; isMacro {
+				return fmt.Errorf("Macro %s cannot be used in a pipeline", word)
+			}
+			return errUnknownCommand
+		}
+		if i > 0 && cmd.input == "" && !cmd.stream {
+			return fmt.Errorf("%s does not read from a pipe", cmd.name)
+		}
+		cmds[i] = cmd
+	}
+
+	var in []item
+	for i, cmd := range cmds {
+		a := parseArgs(stages[i][1:], cmd.boolFlags())
+		render := i == len(cmds)-1
+		if i == 0 || cmd.stream {
+			a.input = in
+			out, err := r.execute(cmd, a, render)
+			if err != nil {
+				return err
+			}
+			in = out
+			continue
+		}
+
+		next := []item{}
+		for _, it := range in {
+			if it.Kind != cmd.input {
+				return fmt.Errorf("%s takes %s, but was given the %s %s", cmd.name, cmd.input, it.Kind, it.Name)
+			}
+			itemArgs := a
+			itemArgs.positional = append([]string{it.Name}, a.positional...)
+			out, err := r.execute(cmd, itemArgs, render)
+			if err != nil {
+				return err
+			}
+			next = append(next, out...)
+		}
+		in = next
+	}
+	return nil
+}
+
+// execute validates and runs a single command. A rendered result is written to the
+// output, otherwise it is turned into items for the next command of the pipeline.
+func (r *commandRegistry) execute(cmd *cliCommand, a args, render bool) ([]item, error) {
+	if err := cmd.validate(a); err != nil {
+		return nil, err
+	}
+
+	res, err := cmd.callback(r.config, a)
+	if res == nil {
+		return nil, err
+	}
+	if render {
+		//Results are rendered even when the command also reports an error, so partial output is not lost
+		if renderErr := r.config.render(res); renderErr != nil && err == nil {
+			err = renderErr
+		}
+		return nil, err
+	}
+
+	source, ok := res.(itemSource)
+	if !ok {
+		return nil, fmt.Errorf("The output of %s cannot be piped", cmd.name)
+	}
+	return append([]item{}, source.items()...), err
+}
+
+// filterCriteria are the conditions given to filter as key=value pairs
+type filterCriteria struct {
+	names  []string
+	types  []string
+	caught string
+}
+
+func parseFilter(positional []string) (filterCriteria, error) {
+	criteria := filterCriteria{}
+	for _, arg := range positional {
+		key, value, found := strings.Cut(strings.ToLower(arg), "=")
+		if !found || value == "" {
+			return criteria, fmt.Errorf("Invalid condition '%s'. Use key=value with name, type or caught", arg)
+		}
+		switch key {
+		case "name":
+			if _, err := path.Match(value, ""); err != nil {
+				return criteria, fmt.Errorf("Invalid name pattern '%s'", value)
+			}
+			criteria.names = append(criteria.names, value)
+		case "type":
+			criteria.types = append(criteria.types, value)
+		case "caught":
+			if value != "yes" && value != "no" {
+				return criteria, fmt.Errorf("caught must be yes or no, not '%s'", value)
+			}
+			criteria.caught = value
+		default:
+			return criteria, fmt.Errorf("Unknown filter '%s'. Filter by name, type or caught", key)
+		}
+	}
+	return criteria, nil
+}
+
+// matchName reports whether name matches every name pattern
+func (f filterCriteria) matchName(name string) bool {
+	for _, pattern := range f.names {
+		if matched, _ := path.Match(pattern, name); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func commandFilter(cfg *config, a args) (result, error) {
+	if a.input == nil {
+		return nil, errors.New("filter reads from a pipe, e.g. pokedex | filter type=water")
+	}
+	criteria, err := parseFilter(a.positional)
+	if err != nil {
+		return nil, err
+	}
+
+	//Check names and the Pokedex first, so only the remaining Pokemon need fetching for their types
+	list := itemList{Items: []item{}}
+	for _, it := range a.input {
+		if it.Kind != itemPokemon && (len(criteria.types) > 0 || criteria.caught != "") {
+			return nil, fmt.Errorf("Only Pokemon can be filtered by type or caught, not the %s %s", it.Kind, it.Name)
+		}
+		if !criteria.matchName(it.Name) {
+			continue
+		}
+		if criteria.caught != "" {
+			_, caught := cfg.pokedex[it.Name]
+			if caught != (criteria.caught == "yes") {
+				continue
+			}
+		}
+		list.Items = append(list.Items, it)
+	}
+	if len(criteria.types) == 0 || len(list.Items) == 0 {
+		return list, nil
+	}
+
+	types, err := fetchTypes(list.Items, cfg)
+	if err != nil {
+		return nil, err
+	}
+	matched := list.Items[:0]
+	for i, it := range list.Items {
+		hasAll := true
+		for _, t := range criteria.types {
+			hasAll = hasAll && slices.Contains(types[i], t)
+		}
+		if hasAll {
+			matched = append(matched, it)
+		}
+	}
+	list.Items = matched
+	return list, nil
+}
+
+// fetchTypes returns the types of each Pokemon, using the Pokedex for caught ones
+// and fetching the rest at once
+func fetchTypes(pokemon []item, cfg *config) ([][]string, error) {
+	types := make([][]string, len(pokemon))
+	urls := []string{}
+	missing := []int{}
+	for i, it := range pokemon {
+		if mon, caught := cfg.pokedex[it.Name]; caught {
+			types[i] = mon.Types
+			continue
+		}
+		urls = append(urls, "https://pokeapi.co/api/v2/pokemon/"+it.Name)
+		missing = append(missing, i)
+	}
+
+	results, err := api.FetchMany(urls, cfg.pokecache, cfg.concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching Pokemon types: %w", err)
+	}
+	for j, fetched := range results {
+		var mon api.Pokemon
+		if err := json.Unmarshal(fetched.Body, &mon); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling JSON: %w", err)
+		}
+		types[missing[j]] = pokemonTypes(mon)
+	}
+	return types, nil
+}
+
+// itemList is the stream of items left after filtering
+type itemList struct {
+	Items []item `json:"items"`
+}
+
+func (l itemList) items() []item {
+	return l.Items
+}
+
+func (l itemList) text(w io.Writer) error {
+	if len(l.Items) == 0 {
+		fmt.Fprintln(w, "Nothing matched")
+		return nil
+	}
+	for _, it := range l.Items {
+		fmt.Fprintln(w, it.DisplayName)
+	}
+	return nil
+}
+
+func (l itemList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Items))
+	for i, it := range l.Items {
+		rows[i] = []string{it.Kind, it.Name, it.DisplayName}
+	}
+	return []string{"kind", "name", "display_name"}, rows
+}
+
+// The results below can be piped into commands that take Pokemon or areas
+
+func (l areaList) items() []item {
+	items := make([]item, len(l.Areas))
+	for i, area := range l.Areas {
+		items[i] = item{Kind: itemArea, Name: area.Name, DisplayName: area.DisplayName}
+	}
+	return items
+}
+
+func (e exploreResult) items() []item {
+	items := make([]item, len(e.Pokemon))
+	for i, pokemon := range e.Pokemon {
+		items[i] = item{Kind: itemPokemon, Name: pokemon.Name, DisplayName: pokemon.DisplayName}
+	}
+	return items
+}
+
+func (t encounterTable) items() []item {
+	items := []item{}
+	seen := map[string]bool{}
+	for _, row := range t.Encounters {
+		if !seen[row.Pokemon] {
+			seen[row.Pokemon] = true
+			items = append(items, item{Kind: itemPokemon, Name: row.Pokemon, DisplayName: row.DisplayName})
+		}
+	}
+	return items
+}
+
+func (c catchResult) items() []item {
+	if !c.Caught {
+		return nil
+	}
+	return []item{{Kind: itemPokemon, Name: c.Pokemon.Name, DisplayName: c.Pokemon.DisplayName}}
+}
+
+func (t travelResult) items() []item {
+	return []item{{Kind: itemArea, Name: t.Area.Name, DisplayName: t.Area.DisplayName}}
+}
+
+func (e wildEncounter) items() []item {
+	return []item{{Kind: itemPokemon, Name: e.Pokemon, DisplayName: e.DisplayName}}
+}
+
+func (n noEncounter) items() []item {
+	return []item{}
+}
+
+func (p pokemonDetails) items() []item {
+	return []item{{Kind: itemPokemon, Name: p.Name, DisplayName: p.DisplayName}}
+}
+
+func (d pokedexResult) items() []item {
+	items := []item{}
+	for _, entry := range d.Species {
+		for _, name := range entry.Pokemon {
+			display := name
+			if name == entry.Name {
+				display = entry.DisplayName
+			}
+			items = append(items, item{Kind: itemPokemon, Name: name, DisplayName: display})
+		}
+	}
+	return items
+}
+
+func (r whereResult) items() []item {
+	items := make([]item, len(r.Areas))
+	for i, area := range r.Areas {
+		items[i] = item{Kind: itemArea, Name: area.Name, DisplayName: area.DisplayName}
+	}
+	return items
+}
+
+func (l formList) items() []item {
+	items := make([]item, len(l.Varieties))
+	for i, variety := range l.Varieties {
+		items[i] = item{Kind: itemPokemon, Name: variety.Name, DisplayName: variety.DisplayName}
+	}
+	return items
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizePipeline(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected [][]string
+	}{
+		{"pokedex", [][]string{{"pokedex"}}},
+		{"pokedex | filter type=water|inspect", [][]string{{"pokedex"}, {"filter", "type=water"}, {"inspect"}}},
+		{`say "a | b" c\|d`, [][]string{{"say", "a | b", "c|d"}}},
+		{"map |", [][]string{{"map"}, {}}},
+	}
+	for _, c := range cases {
+		actual, err := tokenizePipeline(c.input)
+		if err != nil || !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%q: expected %q, got %q (%v)", c.input, c.expected, actual, err)
+		}
+	}
+
+	//Outside a pipeline | is an ordinary character
+	if tokens, _ := tokenize("say a|b"); !reflect.DeepEqual(tokens, []string{"say", "a|b"}) {
+		t.Errorf("expected | to stay in the word, got %q", tokens)
+	}
+}
+
+func TestPipelineCountsOnce(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	for _, name := range []string{"magikarp", "gastrodon"} {
+		mon, err := fetchPokemon(name, commands.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commands.config.pokedex[name] = newDexPokemon(mon)
+	}
+
+	var err error
+	captureOutput(commands, func() { err = commands.run("pokedex | inspect") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commands.config.session.commands != 1 || commands.config.stats.Commands != 1 {
+		t.Errorf("expected the pipeline to count as one command, got %d in the session and %d in the profile",
+			commands.config.session.commands, commands.config.stats.Commands)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.rng = rand.New(rand.NewSource(1))
+	for _, name := range []string{"magikarp", "gastrodon"} {
+		mon, err := fetchPokemon(name, commands.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commands.config.pokedex[name] = newDexPokemon(mon)
+	}
+	commands.config.encounter = &wildEncounter{Pokemon: "magikarp", DisplayName: "Magikarp"}
+
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{"explore pastoria-city-area | filter name=*karp | catch", []string{"Throwing a Pokeball at Magikarp..."}},
+		{"explore pastoria-city-area | filter caught=yes", []string{"magikarp", "gastrodon"}},
+		{"explore pastoria-city-area | filter name=gastro* type=ground", []string{"gastrodon"}},
+		{"pokedex | filter type=ground | inspect", []string{"Name: Gastrodon"}},
+		{"pokedex | filter type=fire", []string{"Nothing matched"}},
+		{"where magikarp | filter name=pastoria*", []string{"pastoria-city-area"}},
+	}
+	for _, c := range cases {
+		var err error
+		out := captureOutput(commands, func() { err = commands.run(c.line) })
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.line, err)
+			continue
+		}
+		for _, line := range c.expected {
+			if !strings.Contains(out, line) {
+				t.Errorf("%s: expected %q in output %q", c.line, line, out)
+			}
+		}
+		if strings.Contains(out, "Exploring") || strings.Contains(out, "Your Pokedex") {
+			t.Errorf("%s: expected only the last command to be shown, got %q", c.line, out)
+		}
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+	commands.config.macros["hunt"] = []string{"explore $1"}
+
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{"pokedex | set", "set does not read from a pipe"},
+		{"set | catch", "The output of set cannot be piped"},
+		{"map | catch", "catch takes pokemon, but was given the area canalave-city-area"},
+		{"where magikarp | filter type=water", "Only Pokemon can be filtered by type or caught"},
+		{"filter type=water", "filter reads from a pipe"},
+		{"pokedex | filter color=blue", "Unknown filter 'color'"},
+		{"pokedex |", "Missing command in pipeline"},
+		{"map | hunt", "Macro hunt cannot be used in a pipeline"},
+	}
+	for _, c := range cases {
+		var err error
+		captureOutput(commands, func() { err = commands.run(c.line) })
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.line, c.expected, err)
+		}
+	}
+}
+
+func TestAliasPipeline(t *testing.T) {
+	commands := createRegistry()
+	commands.config.userAliases["caught"] = "pokedex | filter"
+	commands.config.pokedex["magikarp"] = dexPokemon{Name: "magikarp"}
+
+	out := captureOutput(commands, func() {
+		if err := commands.run("caught name=*karp"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if out != "magikarp\n" {
+		t.Errorf("expected the alias to expand to a pipeline, got %q", out)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/savefile"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultProfile is used when neither -profile nor POKEDEX_PROFILE picks one
+const defaultProfile = "default"
+
+// validProfile matches the names a profile may have, which are also directory names
+var validProfile = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func profilesDir() string {
+	return filepath.Join(dataDir(), "profiles")
+}
+
+func profileSaveFile(name string) string {
+	return filepath.Join(profilesDir(), name, "save.json")
+}
+
+func profileExists(name string) bool {
+	info, err := os.Stat(filepath.Join(profilesDir(), name))
+	return err == nil && info.IsDir()
+}
+
+// startupProfile picks the profile from the -profile flag, then POKEDEX_PROFILE
+func startupProfile(flagValue string) (string, error) {
+	name := flagValue
+	if name == "" {
+		name = os.Getenv("POKEDEX_PROFILE")
+	}
+	if name == "" {
+		return defaultProfile, nil
+	}
+	return checkProfileName(name)
+}
+
+func checkProfileName(name string) (string, error) {
+	name = strings.ToLower(name)
+	if !validProfile.MatchString(name) {
+		return "", fmt.Errorf("Invalid profile name '%s'. Use letters, digits, - and _", name)
+	}
+	return name, nil
+}
+
+// migrateLegacySave moves the save file from before profiles existed into the default profile
+func migrateLegacySave() error {
+	legacy := filepath.Join(dataDir(), "save.json")
+	target := profileSaveFile(defaultProfile)
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return fmt.Errorf("Error creating profile directory: %w", err)
+	}
+	if err := os.Rename(legacy, target); err != nil {
+		return fmt.Errorf("Error moving save file into the default profile: %w", err)
+	}
+	return nil
+}
+
+// useProfile makes name the active profile, starting from its save file
+func (c *config) useProfile(name string) error {
+	c.profile = name
+	return c.restore(profileSaveFile(name))
+}
+
+func commandProfile(cfg *config, a args) (result, error) {
+	action := strings.ToLower(a.arg(0))
+	if action == "" || action == "list" {
+		return listProfiles(cfg)
+	}
+
+	if a.arg(1) == "" {
+		return nil, fmt.Errorf("Missing profile name. Usage: profile %s <name>", action)
+	}
+	name, err := checkProfileName(a.arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case "new":
+		if profileExists(name) || name == cfg.profile {
+			return nil, fmt.Errorf("Profile %s already exists", name)
+		}
+		fresh := &config{}
+		fresh.resetGame()
+		if err := fresh.saveGame(profileSaveFile(name)); err != nil {
+			return nil, err
+		}
+		return messageResult{Message: fmt.Sprintf("Created profile %s. Use it with profile switch %s", name, name)}, nil
+	case "switch":
+		if name == cfg.profile {
+			return nil, fmt.Errorf("Already using profile %s", name)
+		}
+		if !profileExists(name) {
+			return nil, fmt.Errorf("No profile named %s. Create it with profile new %s", name, name)
+		}
+		if err := cfg.autosave(); err != nil {
+			return nil, fmt.Errorf("Error saving profile %s: %w", cfg.profile, err)
+		}
+		err := cfg.useProfile(name)
+		return messageResult{Message: fmt.Sprintf("Switched to profile %s with %d Pokemon", name, len(cfg.caught))}, err
+	case "delete":
+		if name == cfg.profile {
+			return nil, fmt.Errorf("Cannot delete profile %s while using it", name)
+		}
+		if !profileExists(name) {
+			return nil, fmt.Errorf("No profile named %s", name)
+		}
+		if err := os.RemoveAll(filepath.Join(profilesDir(), name)); err != nil {
+			return nil, fmt.Errorf("Error deleting profile: %w", err)
+		}
+		return messageResult{Message: fmt.Sprintf("Deleted profile %s", name)}, nil
+	}
+	return nil, fmt.Errorf("Unknown profile action '%s'. Usage: profile [list | new <name> | switch <name> | delete <name>]", action)
+}
+
+// profileNames returns every profile with a directory, along with the active one
+func profileNames(cfg *config) []string {
+	names := []string{}
+	entries, _ := os.ReadDir(profilesDir())
+	for _, entry := range entries {
+		if entry.IsDir() && validProfile.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	if cfg.profile != "" && !profileExists(cfg.profile) {
+		names = append(names, cfg.profile)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func listProfiles(cfg *config) (result, error) {
+	list := profileList{Profiles: []profileEntry{}}
+	for _, name := range profileNames(cfg) {
+		entry := profileEntry{Name: name, Current: name == cfg.profile}
+		if entry.Current {
+			entry.Pokemon, entry.Stats = len(cfg.caught), cfg.stats
+		} else {
+			var data saveData
+			err := savefile.New(profileSaveFile(name), saveVersion, saveMigrations).Load(&data)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				entry.Error = err.Error()
+			}
+			entry.Pokemon, entry.Stats = len(data.Caught), data.Stats
+		}
+		list.Profiles = append(list.Profiles, entry)
+	}
+	return list, nil
+}
+
+// profileList is every trainer profile with what it has caught
+type profileList struct {
+	Profiles []profileEntry `json:"profiles"`
+}
+
+type profileEntry struct {
+	Name    string       `json:"name"`
+	Current bool         `json:"current"`
+	Pokemon int          `json:"pokemon"`
+	Stats   trainerStats `json:"stats"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func (l profileList) text(w io.Writer) error {
+	for _, p := range l.Profiles {
+		marker := " "
+		if p.Current {
+			marker = "*"
+		}
+		if p.Error != "" {
+			fmt.Fprintf(w, "%s %s: unreadable (%s)\n", marker, p.Name, p.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s: %d Pokemon, caught %d of %d attempts, %d areas explored, %d sessions\n", marker, p.Name,
+			p.Pokemon, p.Stats.Caught, p.Stats.CatchAttempts, len(p.Stats.AreasExplored), p.Stats.Sessions)
+	}
+	return nil
+}
+
+func (l profileList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Profiles))
+	for i, p := range l.Profiles {
+		rows[i] = []string{p.Name, strconv.FormatBool(p.Current), strconv.Itoa(p.Pokemon), strconv.Itoa(p.Stats.Caught),
+			strconv.Itoa(p.Stats.CatchAttempts), strconv.Itoa(len(p.Stats.AreasExplored)), strconv.Itoa(p.Stats.Sessions)}
+	}
+	return []string{"name", "current", "pokemon", "caught", "catch_attempts", "areas_explored", "sessions"}, rows
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/savefile"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	commands := createRegistry()
+	cfg := commands.config
+	if err := cfg.useProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	cfg.addCaught("magikarp", api.Pokemon{Name: "magikarp"}, defaultBall, "", 5)
+	cfg.countCatch("magikarp", true)
+
+	lines := []string{"profile new misty", "profile switch misty", "set language fr", "profile", "profile switch default"}
+	out := captureOutput(commands, func() {
+		for _, line := range lines {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+		}
+	})
+	expected := []string{
+		"Created profile misty. Use it with profile switch misty",
+		"Switched to profile misty with 0 Pokemon",
+		"language set to fr",
+		"  default: 1 Pokemon, caught 1 of 1 attempts, 0 areas explored, 1 sessions",
+		"* misty: 0 Pokemon, caught 0 of 0 attempts, 0 areas explored, 1 sessions",
+		"Switched to profile default with 1 Pokemon",
+	}
+	if out != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("expected %q, got %q", strings.Join(expected, "\n")+"\n", out)
+	}
+
+	//Each profile keeps its own settings
+	if cfg.language != defaultLanguage || cfg.stats.Sessions != 2 {
+		t.Errorf("expected the default profile's settings back, got %s after %d sessions", cfg.language, cfg.stats.Sessions)
+	}
+	var misty saveData
+	if err := savefile.New(profileSaveFile("misty"), saveVersion, saveMigrations).Load(&misty); err != nil || misty.Settings.Language != "fr" {
+		t.Errorf("expected misty to be saved with language fr, got %+v (%v)", misty.Settings, err)
+	}
+
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{"profile new misty", "Profile misty already exists"},
+		{"profile switch brock", "No profile named brock"},
+		{"profile delete default", "Cannot delete profile default while using it"},
+		{"profile new ../brock", "Invalid profile name"},
+		{"profile new", "Missing profile name"},
+	}
+	for _, c := range cases {
+		if err := commands.run(c.line); err == nil || !strings.HasPrefix(err.Error(), c.expected) {
+			t.Errorf("%s: expected %q, got %v", c.line, c.expected, err)
+		}
+	}
+
+	captureOutput(commands, func() {
+		if err := commands.run("profile delete misty"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if profileExists("misty") {
+		t.Errorf("expected misty to be deleted")
+	}
+}
+
+func TestStartupProfile(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("POKEDEX_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	//A save from before profiles is moved into the default profile and migrated
+	legacy := filepath.Join(data, "pokedexcli", "save.json")
+	v1 := map[string]any{"pokedex": map[string]api.Pokemon{"magikarp": {Name: "magikarp"}}}
+	if err := savefile.New(legacy, 1, nil).Save(v1); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	if code := run([]string{"-c", "profile"}, os.Stdin, stdout, stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "* default: 1 Pokemon, caught 1 of 1 attempts, 0 areas explored, 1 sessions\n" {
+		t.Errorf("unexpected profiles %q", stdout.String())
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		t.Errorf("expected the old save file to be moved")
+	}
+
+	//The environment picks the profile unless the flag does
+	t.Setenv("POKEDEX_PROFILE", "ash")
+	for argv, expected := range map[string]string{"": "* ash", "-profile=brock": "* brock"} {
+		stdout.Reset()
+		args := []string{"-c", "profile"}
+		if argv != "" {
+			args = append([]string{argv}, args...)
+		}
+		if code := run(args, os.Stdin, stdout, stderr); code != 0 || !strings.Contains(stdout.String(), expected) {
+			t.Errorf("%q: expected %q, got %q (exit code %d)", argv, expected, stdout.String(), code)
+		}
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-profile", "a/b", "-c", "profile"}, os.Stdin, stdout, stderr); code != 2 || !strings.HasPrefix(stderr.String(), "Invalid profile name") {
+		t.Errorf("expected an invalid profile to be rejected, got exit code %d and %q", code, stderr.String())
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// errUnknownCommand is returned when the first word of a line is not a command or alias
+var errUnknownCommand = errors.New("Unknown command")
+
+// errExit is returned by the exit command to end the session once shutdown hooks have run
+var errExit = errors.New("exit")
+
+// flagSpec declares a --flag a command accepts. Flags without a value placeholder are boolean.
+type flagSpec struct {
+	name        string
+	value       string
+	description string
+}
+
+type cliCommand struct {
+	name        string
+	aliases     []string
+	usage       string
+	description string
+	minArgs     int
+	maxArgs     int
+	flags       []flagSpec
+	examples    []string
+	callback    func(cfg *config, a args) (result, error)
+	complete    func(cfg *config, prev []string) []string
+
+	//input is the kind of item the command takes from a pipe, one run per item as
+	//its first argument. Stream commands get every piped item at once in args.input.
+	input  string
+	stream bool
+}
+
+// commandRegistry holds every command by name and alias along with the shared config
+type commandRegistry struct {
+	commands map[string]*cliCommand
+	aliases  map[string]string
+	config   *config
+	hooks    []func() error
+
+	//macroDepth counts macros currently running, to stop runaway recursion
+	macroDepth int
+}
+
+func newRegistry(cfg *config) *commandRegistry {
+	return &commandRegistry{
+		commands: map[string]*cliCommand{},
+		aliases:  map[string]string{},
+		config:   cfg,
+	}
+}
+
+// register adds a command, panicking on duplicate names since that is a programming error
+func (r *commandRegistry) register(cmd cliCommand) {
+	for _, name := range append([]string{cmd.name}, cmd.aliases...) {
+		if _, exists := r.lookup(name); exists {
+			panic(fmt.Sprintf("command %s registered twice", name))
+		}
+	}
+	r.commands[cmd.name] = &cmd
+	for _, alias := range cmd.aliases {
+		r.aliases[alias] = cmd.name
+	}
+}
+
+// onShutdown registers fn to run when the session ends. Hooks run in reverse
+// order of registration, like deferred calls.
+func (r *commandRegistry) onShutdown(fn func() error) {
+	r.hooks = append(r.hooks, fn)
+}
+
+// shutdown runs every shutdown hook once, even if earlier ones fail
+func (r *commandRegistry) shutdown() error {
+	errs := []error{}
+	for i := len(r.hooks) - 1; i >= 0; i-- {
+		if err := r.hooks[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.hooks = nil
+	return errors.Join(errs...)
+}
+
+// lookup finds a command by name or alias
+func (r *commandRegistry) lookup(name string) (*cliCommand, bool) {
+	if cmd, exists := r.commands[name]; exists {
+		return cmd, true
+	}
+	if target, exists := r.aliases[name]; exists {
+		return r.commands[target], true
+	}
+	return nil, false
+}
+
+// names returns every command name in alphabetical order
+func (r *commandRegistry) names() []string {
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// run tokenizes a line of input, expands user aliases and runs the macro or
+// pipeline of commands it names
+func (r *commandRegistry) run(line string) error {
+	stages, err := tokenizePipeline(line)
+	if err != nil {
+		return err
+	}
+	if len(stages) == 1 && len(stages[0]) == 0 {
+		return nil
+	}
+
+	//User aliases expand to their command before lookup and may be pipelines themselves
+	expanded := [][]string{}
+	for _, stage := range stages {
+		if len(stage) == 0 {
+			return errors.New("Missing command in pipeline")
+		}
+		commands, err := r.expandAlias(stage)
+		if err != nil {
+			return err
+		}
+		expanded = append(expanded, commands...)
+	}
+	stages = expanded
+
+	//Command names are case-insensitive, arguments are passed through as typed
+	word := strings.ToLower(stages[0][0])
+	lines, isMacro := r.config.macros[word]
+	cmd, exists := r.lookup(word)
+	if !exists && !isMacro {
+		return errUnknownCommand
+	}
+
+	//While a macro is being recorded, lines are saved for later instead of run
+	if rec := r.config.recording; rec != nil && (isMacro || (cmd.name != "macro" && cmd.name != "exit")) {
+		rec.lines = append(rec.lines, strings.TrimSpace(line))
+		return nil
+	}
+	if isMacro {
+		if len(stages) > 1 {
+			return fmt.Errorf("Macro %s cannot be used in a pipeline", word)
+		}
+		return r.runMacro(word, lines, stages[0][1:])
+	}
+	//A pipeline is one command however many items pass through it, and each line of a
+	//macro is counted when it runs
+	r.config.countCommand()
+	return r.runPipeline(stages)
+}
+
+// complete returns tab completion candidates for the last word of a partial line:
+// command names first, then options or whatever the command offers for that argument
+func (r *commandRegistry) complete(line string) []string {
+	//Only the last command of a pipeline is being typed
+	if i := strings.LastIndex(line, "|"); i >= 0 {
+		line = strings.TrimLeft(line[i+1:], " ")
+	}
+	words := strings.Fields(line)
+	if line == "" || line[len(line)-1] == ' ' {
+		words = append(words, "")
+	}
+	if len(words) == 1 {
+		return r.namesAndAliases()
+	}
+	if expansion, exists := r.config.userAliases[strings.ToLower(words[0])]; exists {
+		words = append(strings.Fields(expansion), words[1:]...)
+	}
+
+	cmd, exists := r.lookup(strings.ToLower(words[0]))
+	if !exists {
+		return nil
+	}
+
+	current := words[len(words)-1]
+	if strings.HasPrefix(current, "--") {
+		candidates := []string{}
+		for _, f := range cmd.flags {
+			candidates = append(candidates, "--"+f.name)
+		}
+		return candidates
+	}
+
+	//Collect the positional arguments before the current word, skipping option values
+	prev := []string{}
+	boolFlags := cmd.boolFlags()
+	for i := 1; i < len(words)-1; i++ {
+		name, isFlag := strings.CutPrefix(words[i], "--")
+		switch {
+		case !isFlag:
+			prev = append(prev, strings.ToLower(words[i]))
+		case !strings.Contains(name, "=") && !slices.Contains(boolFlags, strings.ToLower(name)):
+			if i == len(words)-2 {
+				return nil
+			}
+			i++
+		}
+	}
+
+	if cmd.complete == nil {
+		return nil
+	}
+	return cmd.complete(r.config, prev)
+}
+
+// namesAndAliases returns every command name, alias, user alias and macro in alphabetical order
+func (r *commandRegistry) namesAndAliases() []string {
+	names := r.names()
+	for alias := range r.aliases {
+		names = append(names, alias)
+	}
+	for alias := range r.config.userAliases {
+		names = append(names, alias)
+	}
+	for macro := range r.config.macros {
+		names = append(names, macro)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *cliCommand) boolFlags() []string {
+	flags := []string{}
+	for _, f := range c.flags {
+		if f.value == "" {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+// validate checks the argument count and flags before the callback runs
+func (c *cliCommand) validate(a args) error {
+	if len(a.positional) < c.minArgs {
+		return fmt.Errorf("Missing argument. Usage: %s", c.usage)
+	}
+	if c.maxArgs >= 0 && len(a.positional) > c.maxArgs {
+		return fmt.Errorf("Too many arguments. Usage: %s", c.usage)
+	}
+
+	for name, val := range a.flags {
+		spec, exists := c.flag(name)
+		if !exists {
+			return fmt.Errorf("Unknown option --%s. Usage: %s", name, c.usage)
+		}
+		if spec.value != "" && val == "" {
+			return fmt.Errorf("Option --%s requires a value. Usage: %s", name, c.usage)
+		}
+		if spec.value == "" && val != "" {
+			return fmt.Errorf("Option --%s does not take a value. Usage: %s", name, c.usage)
+		}
+	}
+	return nil
+}
+
+func (c *cliCommand) flag(name string) (flagSpec, bool) {
+	for _, f := range c.flags {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+// commandUsage is the full help of a single command
+type commandUsage struct {
+	Name        string        `json:"name"`
+	Aliases     []string      `json:"aliases,omitempty"`
+	Usage       string        `json:"usage"`
+	Description string        `json:"description"`
+	Options     []optionUsage `json:"options,omitempty"`
+	Examples    []string      `json:"examples,omitempty"`
+}
+
+type optionUsage struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	Description string `json:"description"`
+}
+
+func (c *cliCommand) help() commandUsage {
+	u := commandUsage{
+		Name:        c.name,
+		Aliases:     c.aliases,
+		Usage:       c.usage,
+		Description: c.description,
+		Examples:    c.examples,
+	}
+	for _, f := range c.flags {
+		u.Options = append(u.Options, optionUsage{Name: f.name, Value: f.value, Description: f.description})
+	}
+	return u
+}
+
+// text writes the usage, aliases, options and examples of the command
+func (u commandUsage) text(w io.Writer) error {
+	fmt.Fprintf(w, "%s: %s\n", u.Name, u.Description)
+	fmt.Fprintf(w, "Usage: %s\n", u.Usage)
+	if len(u.Aliases) > 0 {
+		fmt.Fprintf(w, "Aliases: %s\n", strings.Join(u.Aliases, ", "))
+	}
+
+	if len(u.Options) > 0 {
+		fmt.Fprintln(w, "Options:")
+		width := 0
+		names := make([]string, len(u.Options))
+		for i, o := range u.Options {
+			names[i] = "--" + o.Name
+			if o.Value != "" {
+				names[i] += " <" + o.Value + ">"
+			}
+			width = max(width, len(names[i]))
+		}
+		for i, o := range u.Options {
+			fmt.Fprintf(w, "  %-*s  %s\n", width, names[i], o.Description)
+		}
+	}
+
+	if len(u.Examples) > 0 {
+		fmt.Fprintln(w, "Examples:")
+		for _, example := range u.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+	return nil
+}
+
+func (u commandUsage) table() ([]string, [][]string) {
+	return []string{"name", "usage", "description"}, [][]string{{u.Name, u.Usage, u.Description}}
+}
+
+// commandList is the list of every command shown by plain help
+type commandList struct {
+	Commands []commandSummary `json:"commands"`
+}
+
+type commandSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (l commandList) text(w io.Writer) error {
+	fmt.Fprintln(w, "Welcome to the Pokedex!")
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w)
+	for _, c := range l.Commands {
+		fmt.Fprintf(w, "%s: %s\n", c.Name, c.Description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use help <command> for usage, options and examples")
+	return nil
+}
+
+func (l commandList) table() ([]string, [][]string) {
+	rows := make([][]string, len(l.Commands))
+	for i, c := range l.Commands {
+		rows[i] = []string{c.Name, c.Description}
+	}
+	return []string{"name", "description"}, rows
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistryValidation(t *testing.T) {
+	commands := createRegistry()
+
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{"catch", "Missing argument. Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]"},
+		{"catch magikarp gyarados", "Too many arguments. Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]"},
+		{"catch magikarp --shiny", "Unknown option --shiny. Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]"},
+		{"catch magikarp --ball", "Option --ball requires a value. Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]"},
+		{"explore pastoria-city-area --details=yes", "Option --details does not take a value. Usage: explore <area> [--details | --table [--version <version>] [--method <method>]]"},
+		{"explore pastoria-city-area --details --table", "Options --details and --table cannot be combined. Usage: explore <area> [--details | --table [--version <version>] [--method <method>]]"},
+		{"explore pastoria-city-area --version platinum", "Option --version requires --table. Usage: explore <area> [--details | --table [--version <version>] [--method <method>]]"},
+		{"explore pastoria-city-area --details --method good-rod", "Option --method requires --table. Usage: explore <area> [--details | --table [--version <version>] [--method <method>]]"},
+		{"map 2", "Too many arguments. Usage: map"},
+	}
+	for _, c := range cases {
+		err := commands.run(c.line)
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("%s: expected error %q, got %v", c.line, c.expected, err)
+		}
+	}
+
+	if err := commands.run("teleport"); !errors.Is(err, errUnknownCommand) {
+		t.Errorf("expected unknown command error, got %v", err)
+	}
+}
+
+func TestRegistryAliases(t *testing.T) {
+	commands := createRegistry()
+
+	cmd, exists := commands.lookup("dex")
+	if !exists || cmd.name != "pokedex" {
+		t.Fatalf("expected dex to be an alias of pokedex")
+	}
+
+	out := captureOutput(commands, func() { commands.run("DEX") })
+	if out != "Your Pokedex:\n" {
+		t.Errorf("expected alias to run pokedex, got:\n%s", out)
+	}
+}
+
+func TestRegistryDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected registering a duplicate alias to panic")
+		}
+	}()
+	commands := createRegistry()
+	commands.register(cliCommand{name: "dex", usage: "dex"})
+}
+
+func TestCommandHelpForCommand(t *testing.T) {
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("help catch") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "catch: Try to catch the wild Pokemon you encountered!\n" +
+		"Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]\n" +
+		"Options:\n" +
+		"  --form <form>      Target a specific form, e.g. mega-x\n" +
+		"  --ball <ball>      Throw a poke, great, ultra or master ball\n" +
+		"  --nickname <name>  Give the Pokemon a nickname if it is caught\n" +
+		"Examples:\n" +
+		"  catch magikarp\n" +
+		"  catch 129\n" +
+		"  catch charizard --form mega-x\n" +
+		"  catch gyarados --ball ultra --nickname Tsunami\n"
+	if out != expected {
+		t.Errorf("unexpected help output:\n%s\nexpected:\n%s", out, expected)
+	}
+
+	out = captureOutput(commands, func() { err = commands.run("help") })
+	if !strings.Contains(out, "pokedex: See the list of Pokemon you have caught\n") {
+		t.Errorf("expected command list, got:\n%s", out)
+	}
+
+	captureOutput(commands, func() { err = commands.run("help teleport") })
+	if err == nil {
+		t.Errorf("expected error for help on an unknown command")
+	}
+}
+
+func TestRegistryShutdown(t *testing.T) {
+	commands := newRegistry(&config{})
+	order := []string{}
+	commands.onShutdown(func() error { order = append(order, "first"); return nil })
+	commands.onShutdown(func() error { order = append(order, "second"); return errors.New("disk full") })
+	commands.onShutdown(func() error { order = append(order, "third"); return nil })
+
+	err := commands.shutdown()
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the hook error to be returned, got %v", err)
+	}
+	if strings.Join(order, ",") != "third,second,first" {
+		t.Errorf("expected hooks to run in reverse order, got %v", order)
+	}
+
+	if err := commands.shutdown(); err != nil || len(order) != 3 {
+		t.Errorf("expected hooks to run only once")
+	}
+}
+
+func TestCommandExit(t *testing.T) {
+	commands := createRegistry()
+	defer commands.shutdown()
+
+	for _, line := range []string{"exit", "QUIT"} {
+		if err := commands.run(line); !errors.Is(err, errExit) {
+			t.Errorf("%s: expected errExit, got %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"pokedexcli/internal/lineedit"
+	"strings"
+)
+
+// errorMessage is how a failed command is reported to the user
+func errorMessage(err error) string {
+	if errors.Is(err, errUnknownCommand) {
+		return "Unknown command"
+	}
+	return fmt.Sprintf("An error has occurred: %s", err)
+}
+
+// interactive runs the REPL and then the shutdown hooks, ending with a summary of the session
+func interactive(commands *commandRegistry, editor *lineedit.Editor, stderr io.Writer) error {
+	commands.onShutdown(func() error {
+		return commands.config.render(commands.config.session.summary())
+	})
+	repl(commands, editor, stderr)
+	return commands.shutdown()
+}
+
+// repl reads commands from the editor until exit or the end of input (Ctrl-D).
+// Output goes to the writer in the registry's config and history failures to stderr.
+func repl(commands *commandRegistry, editor *lineedit.Editor, stderr io.Writer) {
+	out := commands.config.out
+	editor.Complete = commands.complete
+	historyFailed := false
+
+	//Begin accepting input
+	for {
+		line, err := editor.ReadLine("Pokedex > ")
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			continue
+		}
+		if err != nil && line == "" {
+			return
+		}
+		//A line comes back with an error when it could not be saved to the history. It still
+		//runs, and the failure is only reported once rather than after every command.
+		if err != nil && !historyFailed {
+			fmt.Fprintf(stderr, "Could not save history: %s\n", err)
+			historyFailed = true
+		}
+
+		//Ask for input if none was provided
+		if strings.TrimSpace(line) == "" {
+			fmt.Fprintln(out, "Please enter a command")
+			continue
+		}
+
+		err = commands.run(line)
+		if errors.Is(err, errExit) {
+			return
+		}
+		if err != nil {
+			fmt.Fprintln(out, errorMessage(err))
+		}
+	}
+}
+
+// runScript runs every line of in as a command without printing prompts. Blank
+// lines and lines starting with # are skipped and failures are reported on stderr
+// with the line they came from. The exit command ends the script early. It
+// returns false if any command failed.
+func runScript(commands *commandRegistry, name string, in io.Reader, stderr io.Writer, stopOnError bool) bool {
+	ok := true
+	scanner := bufio.NewScanner(in)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		err := commands.run(line)
+		if errors.Is(err, errExit) {
+			return ok
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s:%d: %s\n", name, lineNumber, errorMessage(err))
+			ok = false
+			if stopOnError {
+				return false
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "%s: Error reading commands: %s\n", name, err)
+		return false
+	}
+	return ok
+}
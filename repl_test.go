@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
-func TestCleanInpu(t *testing.T) {
+func TestTokenize(t *testing.T) {
 	cases := []struct {
 		input    string
 		expected []string
@@ -15,18 +19,35 @@ func TestCleanInpu(t *testing.T) {
 		},
 		{
 			input:    "THIS IS SPARTA",
-			expected: []string{"this", "is", "sparta"},
+			expected: []string{"THIS", "IS", "SPARTA"},
 		},
 		{
 			input:    "H e l l o M o t o ! ",
-			expected: []string{"h", "e", "l", "l", "o", "m", "o", "t", "o", "!"},
+			expected: []string{"H", "e", "l", "l", "o", "M", "o", "t", "o", "!"},
+		},
+		{
+			input:    `catch "mr-mime" 'farfetch d'`,
+			expected: []string{"catch", "mr-mime", "farfetch d"},
+		},
+		{
+			input:    `say "it's" 'a "quote"' one\ word ""`,
+			expected: []string{"say", "it's", `a "quote"`, "one word", ""},
+		},
+		{
+			input:    "explore pastoria-city-area --version=platinum",
+			expected: []string{"explore", "pastoria-city-area", "--version=platinum"},
 		},
 	}
 
 	for _, c := range cases {
-		actual := cleanInput(c.input)
+		actual, err := tokenize(c.input)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", c.input, err)
+			continue
+		}
 		if len(actual) != len(c.expected) {
 			t.Errorf("%d != %d. Length of result does not match length of expected.", len(actual), len(c.expected))
+			continue
 		}
 		for i := range actual {
 			word := actual[i]
@@ -37,3 +58,160 @@ func TestCleanInpu(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, input := range []string{`catch "pikachu`, `catch 'pikachu`, `catch pikachu\`} {
+		if _, err := tokenize(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tokens := []string{"pastoria-city-area", "--TABLE", "--version", "platinum", "--method=old-rod", "extra", "--details", "--", "--not-a-flag"}
+	a := parseArgs(tokens, []string{"table"})
+
+	expectedPositional := []string{"pastoria-city-area", "extra", "--not-a-flag"}
+	if len(a.positional) != len(expectedPositional) {
+		t.Fatalf("expected positional %v, got %v", expectedPositional, a.positional)
+	}
+	for i := range expectedPositional {
+		if a.arg(i) != expectedPositional[i] {
+			t.Errorf("positional %d: expected %s, got %s", i, expectedPositional[i], a.arg(i))
+		}
+	}
+	if a.arg(5) != "" {
+		t.Errorf("expected missing positional to be empty")
+	}
+
+	if !a.has("table") {
+		t.Errorf("expected --table to be set")
+	}
+	if val, _ := a.flag("version"); val != "platinum" {
+		t.Errorf("expected --version platinum, got %s", val)
+	}
+	if val, _ := a.flag("method"); val != "old-rod" {
+		t.Errorf("expected --method=old-rod, got %s", val)
+	}
+	if val, exists := a.flag("details"); !exists || val != "" {
+		t.Errorf("expected trailing --details to be present without a value")
+	}
+	if a.has("form") {
+		t.Errorf("expected --form to be absent")
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	useCassette(t, "commands")
+	commands := createRegistry()
+
+	script := "# set up\nset language fr\n\nteleport\nexplore pastoria-city-area\n"
+	stderr := &bytes.Buffer{}
+	var ok bool
+	out := captureOutput(commands, func() {
+		ok = runScript(commands, "test.pdx", strings.NewReader(script), stderr, false)
+	})
+
+	if ok {
+		t.Errorf("expected the script to report a failure")
+	}
+	if stderr.String() != "test.pdx:4: Unknown command\n" {
+		t.Errorf("unexpected error output %q", stderr.String())
+	}
+	if strings.Contains(out, "Pokedex >") {
+		t.Errorf("expected no prompts, got:\n%s", out)
+	}
+	if !strings.HasPrefix(out, "language set to fr\nExploring Voilaroc...\n") {
+		t.Errorf("expected commands after the failure to run, got:\n%s", out)
+	}
+}
+
+func TestRunScriptStopOnError(t *testing.T) {
+	commands := createRegistry()
+
+	stderr := &bytes.Buffer{}
+	var ok bool
+	out := captureOutput(commands, func() {
+		ok = runScript(commands, "stdin", strings.NewReader("catch\nset language fr\n"), stderr, true)
+	})
+
+	if ok || out != "" {
+		t.Errorf("expected the script to stop at the first error, got ok=%v output %q", ok, out)
+	}
+	if stderr.String() != "stdin:1: An error has occurred: Missing argument. Usage: catch <pokemon> [--form <form>] [--ball <ball>] [--nickname <name>]\n" {
+		t.Errorf("unexpected error output %q", stderr.String())
+	}
+}
+
+func TestRunScriptStopOnFailedCommand(t *testing.T) {
+	commands := createRegistry()
+
+	//Commands that cannot do what was asked fail the script too, not only invalid input
+	stderr := &bytes.Buffer{}
+	var ok bool
+	out := captureOutput(commands, func() {
+		ok = runScript(commands, "stdin", strings.NewReader("set language fr\ninspect gyarados\nset language de\n"), stderr, true)
+	})
+
+	if ok || out != "language set to fr\n" || commands.config.language != "fr" {
+		t.Errorf("expected the script to stop at inspect, got ok=%v output %q", ok, out)
+	}
+	if stderr.String() != "stdin:2: An error has occurred: You have not caught gyarados yet\n" {
+		t.Errorf("unexpected error output %q", stderr.String())
+	}
+}
+
+func TestRunModes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("POKEDEX_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	script := filepath.Join(dir, "script.pdx")
+	if err := os.WriteFile(script, []byte("set language de\nset\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		argv   []string
+		code   int
+		out    string
+		stderr string
+	}{
+		{[]string{"-c", "set language fr"}, 0, "language set to fr\n", ""},
+		{[]string{"-c", "teleport"}, 1, "", "Unknown command\n"},
+		{[]string{"-c", "mapb"}, 1, "", "An error has occurred: You are already on the first page\n"},
+		{[]string{"-c", "exit"}, 0, "", ""},
+		{[]string{script}, 0, "language set to de\nlanguage: de\noutput: text\nversion: any\n", ""},
+		{[]string{"-stop-on-error", filepath.Join(dir, "missing.pdx")}, 1, "", "Error opening script"},
+		{[]string{"-c", "help", script}, 2, "", "Usage: pokedexcli"},
+	}
+	for _, c := range cases {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		code := run(c.argv, os.Stdin, stdout, stderr)
+		if code != c.code {
+			t.Errorf("%v: expected exit code %d, got %d", c.argv, c.code, code)
+		}
+		if stdout.String() != c.out {
+			t.Errorf("%v: expected output %q, got %q", c.argv, c.out, stdout.String())
+		}
+		if !strings.HasPrefix(stderr.String(), c.stderr) {
+			t.Errorf("%v: expected error output %q, got %q", c.argv, c.stderr, stderr.String())
+		}
+	}
+}
+
+func TestRunScriptExit(t *testing.T) {
+	commands := createRegistry()
+
+	stderr := &bytes.Buffer{}
+	var ok bool
+	out := captureOutput(commands, func() {
+		ok = runScript(commands, "stdin", strings.NewReader("set language fr\nexit\nset language de\n"), stderr, false)
+	})
+
+	if !ok || out != "language set to fr\n" || stderr.Len() != 0 {
+		t.Errorf("expected the script to stop at exit, got ok=%v output %q errors %q", ok, out, stderr.String())
+	}
+	if commands.config.language != "fr" {
+		t.Errorf("expected commands after exit to be skipped")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/savefile"
+)
+
+// saveVersion is the schema version of the save file written by this program
+const saveVersion = 4
+
+// saveMigrations upgrade save files written by older versions, keyed by the version they upgrade from
+var saveMigrations = map[int]savefile.Migration{
+	1: migrateV1,
+	2: migrateV2,
+	3: migrateV3,
+}
+
+// saveData is what is kept in a profile's save file between sessions
+type saveData struct {
+	Pokedex  map[string]dexPokemon `json:"pokedex"`
+	Settings saveSettings          `json:"settings"`
+	Stats    trainerStats          `json:"stats"`
+	Caught   []caughtPokemon       `json:"caught"`
+	NextID   int                   `json:"next_id"`
+}
+
+// saveSettings are the settings a profile keeps
+type saveSettings struct {
+	Language string `json:"language"`
+	Output   string `json:"output"`
+	Version  string `json:"version,omitempty"`
+}
+
+// migrateV1 adds settings and statistics to saves from before profiles, counting
+// every Pokemon in the Pokedex as caught on the first attempt
+func migrateV1(data json.RawMessage) (json.RawMessage, error) {
+	var v1 struct {
+		Pokedex map[string]json.RawMessage `json:"pokedex"`
+	}
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{
+		"pokedex":  v1.Pokedex,
+		"settings": saveSettings{Language: defaultLanguage, Output: defaultOutput},
+		"stats":    trainerStats{CatchAttempts: len(v1.Pokedex), Caught: len(v1.Pokedex)},
+	})
+}
+
+// migrateV2 turns each Pokemon in the Pokedex into a caught Pokemon with its own
+// ID. When, where and at what level they were caught was not kept, so is left out.
+func migrateV2(data json.RawMessage) (json.RawMessage, error) {
+	var v2 map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v2); err != nil {
+		return nil, err
+	}
+	var pokedex map[string]json.RawMessage
+	if err := json.Unmarshal(v2["pokedex"], &pokedex); err != nil {
+		return nil, err
+	}
+
+	caught := []caughtPokemon{}
+	for i, name := range sortedKeys(pokedex) {
+		caught = append(caught, caughtPokemon{ID: i + 1, Species: name, Ball: defaultBall})
+	}
+	var err error
+	if v2["caught"], err = json.Marshal(caught); err != nil {
+		return nil, err
+	}
+	if v2["next_id"], err = json.Marshal(len(caught)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v2)
+}
+
+// migrateV3 keeps only what the Pokedex shows of each Pokemon instead of the whole
+// PokeAPI response, whose sprites, moves and game indices made saves large
+func migrateV3(data json.RawMessage) (json.RawMessage, error) {
+	var v3 map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v3); err != nil {
+		return nil, err
+	}
+	var pokedex map[string]api.Pokemon
+	if err := json.Unmarshal(v3["pokedex"], &pokedex); err != nil {
+		return nil, err
+	}
+
+	slim := map[string]dexPokemon{}
+	for name, pokemon := range pokedex {
+		slim[name] = newDexPokemon(pokemon)
+	}
+	var err error
+	if v3["pokedex"], err = json.Marshal(slim); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v3)
+}
+
+// dataDir is where files the program creates for the user live, following the XDG base directory spec
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "pokedexcli")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "pokedexcli")
+	}
+	return "pokedexcli"
+}
+
+// saveGame writes the Pokedex, caught Pokemon, settings and stats to the save file at path
+func (c *config) saveGame(path string) error {
+	return savefile.New(path, saveVersion, saveMigrations).Save(saveData{
+		Pokedex:  c.pokedex,
+		Settings: saveSettings{Language: c.language, Output: c.savedOutput, Version: c.version},
+		Stats:    c.stats,
+		Caught:   c.caught,
+		NextID:   c.nextID,
+	})
+}
+
+// loadGame replaces the Pokedex, caught Pokemon, settings and stats with those in the save file at path
+func (c *config) loadGame(path string) error {
+	var data saveData
+	if err := savefile.New(path, saveVersion, saveMigrations).Load(&data); err != nil {
+		return err
+	}
+	if data.Pokedex == nil {
+		data.Pokedex = map[string]dexPokemon{}
+	}
+	if data.Caught == nil {
+		data.Caught = []caughtPokemon{}
+	}
+	c.pokedex = data.Pokedex
+	c.caught, c.nextID = data.Caught, data.NextID
+	c.stats = data.Stats
+	if validLanguage(data.Settings.Language) {
+		c.language = data.Settings.Language
+	}
+	if validVersion(data.Settings.Version) {
+		c.version = data.Settings.Version
+	}
+	if validOutput(data.Settings.Output) == nil {
+		c.output, c.savedOutput = data.Settings.Output, data.Settings.Output
+	}
+	return nil
+}
+
+// resetGame empties the Pokedex and returns settings and stats to their defaults
+func (c *config) resetGame() {
+	c.pokedex = map[string]dexPokemon{}
+	c.caught, c.nextID = []caughtPokemon{}, 0
+	c.location, c.encounter = "", nil
+	c.stats = trainerStats{}
+	c.language = defaultLanguage
+	c.version = ""
+	c.output, c.savedOutput = defaultOutput, defaultOutput
+}
+
+// restore starts a session from the save file at path and autosaves to it. A
+// corrupt file is moved aside rather than overwritten, and any other failure
+// turns autosave off so the file is not replaced by an empty Pokedex.
+func (c *config) restore(path string) error {
+	c.resetGame()
+	c.saveFile = path
+	err := c.loadGame(path)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		c.stats.Sessions++
+		return nil
+	}
+
+	if errors.Is(err, savefile.ErrCorrupt) {
+		if renameErr := os.Rename(path, path+".corrupt"); renameErr == nil {
+			c.stats.Sessions++
+			return fmt.Errorf("%w. It was moved to %s.corrupt and you are starting with an empty Pokedex", err, path)
+		}
+	}
+	c.saveFile = ""
+	return fmt.Errorf("%w. Autosave is off for this session", err)
+}
+
+// autosave is run when the session ends to keep what was caught
+func (c *config) autosave() error {
+	if c.saveFile == "" {
+		return nil
+	}
+	return c.saveGame(c.saveFile)
+}
+
+func commandSave(cfg *config, a args) (result, error) {
+	path := a.arg(0)
+	if path == "" {
+		path = cfg.saveFile
+	}
+	if path == "" {
+		return nil, errors.New("No save file to write to. Usage: save [file]")
+	}
+
+	if err := cfg.saveGame(path); err != nil {
+		return nil, err
+	}
+	return messageResult{Message: fmt.Sprintf("Saved %d Pokemon to %s", len(cfg.caught), path)}, nil
+}
+
+func commandLoad(cfg *config, a args) (result, error) {
+	path := a.arg(0)
+	if path == "" {
+		path = cfg.saveFile
+	}
+	if path == "" {
+		return nil, errors.New("No save file to read from. Usage: load [file]")
+	}
+
+	if err := cfg.loadGame(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("No save file at %s", path)
+		}
+		return nil, err
+	}
+	return messageResult{Message: fmt.Sprintf("Loaded %d Pokemon from %s", len(cfg.caught), path)}, nil
+}
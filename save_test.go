@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/savefile"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	commands := createRegistry()
+	commands.config.saveFile = path
+	commands.config.addCaught("magikarp", api.Pokemon{Name: "magikarp", Weight: 100}, defaultBall, "", 5)
+
+	out := captureOutput(commands, func() {
+		for _, line := range []string{"save", "save " + path + ".bak"} {
+			if err := commands.run(line); err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+		}
+	})
+	if out != "Saved 1 Pokemon to "+path+"\nSaved 1 Pokemon to "+path+".bak\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	commands.config.pokedex = map[string]dexPokemon{}
+	out = captureOutput(commands, func() {
+		if err := commands.run("load"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if out != "Loaded 1 Pokemon from "+path+"\n" || commands.config.pokedex["magikarp"].Weight != 100 {
+		t.Errorf("expected magikarp back, got %q and %v", out, commands.config.pokedex)
+	}
+
+	if err := commands.run("load " + filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.HasPrefix(err.Error(), "No save file at") {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	//A corrupt save is kept aside and the session autosaves to a fresh file
+	corrupt := filepath.Join(dir, "corrupt.json")
+	os.WriteFile(corrupt, []byte(`{"version": 1, "data": {"pokedex": {}}, "checksum": "nope"}`), 0600)
+	cfg := createRegistry().config
+	if err := cfg.restore(corrupt); err == nil || !strings.Contains(err.Error(), "moved to") {
+		t.Errorf("expected the corrupt file to be moved, got %v", err)
+	}
+	if _, err := os.Stat(corrupt + ".corrupt"); err != nil || cfg.saveFile != corrupt {
+		t.Errorf("expected the file to be kept as .corrupt and autosave to stay on")
+	}
+
+	//A save from a newer version is left alone
+	newer := filepath.Join(dir, "newer.json")
+	if err := savefile.New(newer, saveVersion+1, nil).Save(saveData{}); err != nil {
+		t.Fatal(err)
+	}
+	cfg = createRegistry().config
+	if err := cfg.restore(newer); err == nil || cfg.saveFile != "" {
+		t.Errorf("expected autosave to be turned off, got %v and %q", err, cfg.saveFile)
+	}
+	if err := cfg.autosave(); err != nil {
+		t.Fatal(err)
+	}
+	if err := savefile.New(newer, saveVersion+1, nil).Load(&saveData{}); err != nil {
+		t.Errorf("expected the newer save to be untouched, got %v", err)
+	}
+}
+
+func TestAutosave(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("POKEDEX_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	cfg := createRegistry().config
+	cfg.pokedex["magikarp"] = dexPokemon{Name: "magikarp"}
+	cfg.caught, cfg.nextID = []caughtPokemon{{ID: 1, Species: "magikarp", Ball: defaultBall}}, 1
+	if err := cfg.saveGame(profileSaveFile(defaultProfile)); err != nil {
+		t.Fatal(err)
+	}
+	if path := profileSaveFile(defaultProfile); path != filepath.Join(data, "pokedexcli", "profiles", "default", "save.json") {
+		t.Errorf("expected the save file in XDG_DATA_HOME, got %s", path)
+	}
+
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	if code := run([]string{"-c", "pokedex"}, os.Stdin, stdout, stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "Your Pokedex:\n - magikarp\n     #1\n" {
+		t.Errorf("expected the saved Pokedex to be restored, got %q", stdout.String())
+	}
+
+	//What the session ends with is saved for the next one
+	backup := filepath.Join(t.TempDir(), "backup.json")
+	cfg.pokedex = map[string]dexPokemon{"gastrodon": {Name: "gastrodon"}}
+	cfg.caught = []caughtPokemon{{ID: 2, Species: "gastrodon", Ball: defaultBall}}
+	if err := cfg.saveGame(backup); err != nil {
+		t.Fatal(err)
+	}
+	if code := run([]string{"-c", "load " + backup}, os.Stdin, stdout, stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	restored := createRegistry().config
+	if err := restored.restore(profileSaveFile(defaultProfile)); err != nil || len(restored.caught) != 1 || restored.pokedex["gastrodon"].Name == "" {
+		t.Errorf("expected gastrodon to be autosaved, got %v (%v)", restored.caught, err)
+	}
+}
+
+func TestMigrateV3(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	magikarp := `{"name":"magikarp","height":9,"weight":100,"species":{"name":"magikarp","url":"https://pokeapi.co/api/v2/pokemon-species/129/"},` +
+		`"stats":[{"base_stat":20,"effort":0,"stat":{"name":"hp","url":""}}],"types":[{"slot":1,"type":{"name":"water","url":""}}],` +
+		`"sprites":{"front_default":"https://example.com/129.png"},"moves":[{"move":{"name":"splash"}}],"game_indices":[{"game_index":129}]}`
+	v3 := map[string]any{
+		"pokedex":  map[string]json.RawMessage{"magikarp": json.RawMessage(magikarp)},
+		"settings": saveSettings{Language: defaultLanguage, Output: defaultOutput},
+		"caught":   []caughtPokemon{{ID: 1, Species: "magikarp", Ball: defaultBall}},
+		"next_id":  1,
+	}
+	if err := savefile.New(path, 3, nil).Save(v3); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := createRegistry().config
+	if err := cfg.loadGame(path); err != nil {
+		t.Fatalf("unexpected error loading a version 3 save: %v", err)
+	}
+	mon := cfg.pokedex["magikarp"]
+	if mon.Species.Name != "magikarp" || mon.Weight != 100 || len(mon.Types) != 1 || mon.Types[0] != "water" || len(mon.Stats) != 1 || mon.Stats[0].Value != 20 {
+		t.Errorf("expected the shown fields to be kept, got %+v", mon)
+	}
+
+	//The sprites, moves and game indices are not written back
+	if err := cfg.saveGame(path); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, section := range []string{"sprites", "moves", "game_indices"} {
+		if strings.Contains(string(saved), section) {
+			t.Errorf("expected %s to be left out of the save file", section)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sessionStats counts what happened since the Pokedex was opened
+type sessionStats struct {
+	commands      int
+	catchAttempts int
+	caught        []string
+	explored      map[string]bool
+}
+
+func (s *sessionStats) explore(area string) {
+	if s.explored == nil {
+		s.explored = map[string]bool{}
+	}
+	s.explored[area] = true
+}
+
+// trainerStats counts what a profile has done over every session and is kept in its save file
+type trainerStats struct {
+	Sessions      int      `json:"sessions"`
+	Commands      int      `json:"commands"`
+	CatchAttempts int      `json:"catch_attempts"`
+	Caught        int      `json:"caught"`
+	AreasExplored []string `json:"areas_explored"`
+}
+
+// countCommand records a command run in both the session and the profile statistics
+func (c *config) countCommand() {
+	c.session.commands++
+	c.stats.Commands++
+}
+
+// countCatch records a thrown Pokeball and whether it caught name
+func (c *config) countCatch(name string, caught bool) {
+	c.session.catchAttempts++
+	c.stats.CatchAttempts++
+	if caught {
+		c.session.caught = append(c.session.caught, name)
+		c.stats.Caught++
+	}
+}
+
+// countExplore records an area being explored
+func (c *config) countExplore(area string) {
+	c.session.explore(area)
+	if !slices.Contains(c.stats.AreasExplored, area) {
+		c.stats.AreasExplored = append(c.stats.AreasExplored, area)
+	}
+}
+
+func (s *sessionStats) summary() sessionSummary {
+	return sessionSummary{
+		Commands:      s.commands,
+		CatchAttempts: s.catchAttempts,
+		Caught:        append([]string{}, s.caught...),
+		AreasExplored: len(s.explored),
+	}
+}
+
+// sessionSummary is shown when an interactive session ends
+type sessionSummary struct {
+	Commands      int      `json:"commands"`
+	CatchAttempts int      `json:"catch_attempts"`
+	Caught        []string `json:"caught"`
+	AreasExplored int      `json:"areas_explored"`
+}
+
+func (s sessionSummary) text(w io.Writer) error {
+	fmt.Fprintln(w, "Session summary:")
+	fmt.Fprintf(w, " - commands run: %d\n", s.Commands)
+	if len(s.Caught) > 0 {
+		fmt.Fprintf(w, " - Pokemon caught: %d of %d attempts (%s)\n", len(s.Caught), s.CatchAttempts, strings.Join(s.Caught, ", "))
+	} else {
+		fmt.Fprintf(w, " - Pokemon caught: 0 of %d attempts\n", s.CatchAttempts)
+	}
+	fmt.Fprintf(w, " - areas explored: %d\n", s.AreasExplored)
+	fmt.Fprintln(w, "Closing the Pokedex... Goodbye!")
+	return nil
+}
+
+func (s sessionSummary) table() ([]string, [][]string) {
+	return []string{"commands", "catch_attempts", "caught", "areas_explored"},
+		[][]string{{strconv.Itoa(s.Commands), strconv.Itoa(s.CatchAttempts), strings.Join(s.Caught, " "), strconv.Itoa(s.AreasExplored)}}
+}
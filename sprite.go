@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"pokedexcli/internal/api"
+	"pokedexcli/internal/termimage"
+	"strconv"
+	"strings"
+)
+
+// spriteSet holds the sprite URLs offered by one game
+type spriteSet struct {
+	front string
+	back  string
+	shiny string
+}
+
+// generations lists the sprite sets of every generation, preferred game first
+func generations(s api.PokemonSprites) [][]spriteSet {
+	v := s.Versions
+	return [][]spriteSet{
+		{
+			{v.GenerationI.RedBlue.FrontDefault, v.GenerationI.RedBlue.BackDefault, ""},
+			{v.GenerationI.Yellow.FrontDefault, v.GenerationI.Yellow.BackDefault, ""},
+		},
+		{
+			{v.GenerationIi.Crystal.FrontDefault, v.GenerationIi.Crystal.BackDefault, v.GenerationIi.Crystal.FrontShiny},
+			{v.GenerationIi.Gold.FrontDefault, v.GenerationIi.Gold.BackDefault, v.GenerationIi.Gold.FrontShiny},
+			{v.GenerationIi.Silver.FrontDefault, v.GenerationIi.Silver.BackDefault, v.GenerationIi.Silver.FrontShiny},
+		},
+		{
+			{v.GenerationIii.RubySapphire.FrontDefault, v.GenerationIii.RubySapphire.BackDefault, v.GenerationIii.RubySapphire.FrontShiny},
+			{v.GenerationIii.Emerald.FrontDefault, "", v.GenerationIii.Emerald.FrontShiny},
+			{v.GenerationIii.FireredLeafgreen.FrontDefault, v.GenerationIii.FireredLeafgreen.BackDefault, v.GenerationIii.FireredLeafgreen.FrontShiny},
+		},
+		{
+			{v.GenerationIv.DiamondPearl.FrontDefault, v.GenerationIv.DiamondPearl.BackDefault, v.GenerationIv.DiamondPearl.FrontShiny},
+			{v.GenerationIv.Platinum.FrontDefault, v.GenerationIv.Platinum.BackDefault, v.GenerationIv.Platinum.FrontShiny},
+			{v.GenerationIv.HeartgoldSoulsilver.FrontDefault, v.GenerationIv.HeartgoldSoulsilver.BackDefault, v.GenerationIv.HeartgoldSoulsilver.FrontShiny},
+		},
+		{
+			{v.GenerationV.BlackWhite.FrontDefault, v.GenerationV.BlackWhite.BackDefault, v.GenerationV.BlackWhite.FrontShiny},
+		},
+		{
+			{v.GenerationVi.XY.FrontDefault, "", v.GenerationVi.XY.FrontShiny},
+			{v.GenerationVi.OmegarubyAlphasapphire.FrontDefault, "", v.GenerationVi.OmegarubyAlphasapphire.FrontShiny},
+		},
+		{
+			{v.GenerationVii.UltraSunUltraMoon.FrontDefault, "", v.GenerationVii.UltraSunUltraMoon.FrontShiny},
+		},
+		{
+			{v.GenerationViii.Icons.FrontDefault, "", ""},
+		},
+	}
+}
+
+var romanGenerations = []string{"i", "ii", "iii", "iv", "v", "vi", "vii", "viii"}
+
+// parseGeneration accepts 1-8 or i-viii, optionally prefixed with "gen"
+func parseGeneration(s string) (int, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "generation-"), "gen")
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= len(romanGenerations) {
+		return n, true
+	}
+	for i, roman := range romanGenerations {
+		if s == roman {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// spriteURL picks the sprite for view ("front", "back" or "shiny"). A generation of 0
+// uses the current default sprites.
+func spriteURL(s api.PokemonSprites, view string, generation int) string {
+	sets := []spriteSet{{s.FrontDefault, s.BackDefault, s.FrontShiny}}
+	if generation > 0 {
+		sets = generations(s)[generation-1]
+	}
+	for _, set := range sets {
+		url := set.front
+		switch view {
+		case "back":
+			url = set.back
+		case "shiny":
+			url = set.shiny
+		}
+		if url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+func commandSprite(cfg *config, a args) (result, error) {
+	name := a.arg(0)
+	view, generation := "front", 0
+	for _, field := range a.positional[1:] {
+		field = strings.ToLower(field)
+		switch field {
+		case "front", "back", "shiny":
+			view = field
+		default:
+			gen, ok := parseGeneration(field)
+			if !ok {
+				return nil, fmt.Errorf("Unknown sprite option '%s'. Use front, back, shiny or a generation from 1 to 8", field)
+			}
+			generation = gen
+		}
+	}
+
+	name, err := cfg.resolvePokemon(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pokemon, err := fetchPokemon(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sprites, err := pokemon.DecodeSprites()
+	if err != nil {
+		return nil, err
+	}
+
+	url := spriteURL(sprites, view, generation)
+	if url == "" {
+		return nil, fmt.Errorf("No %s sprite available for %s", view, pokemon.Name)
+	}
+
+	data, err := api.Download(url, cfg.pokecache)
+	if err != nil {
+		return nil, fmt.Errorf("Error downloading sprite: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding sprite: %w", err)
+	}
+
+	return spriteResult{Pokemon: pokemon.Name, View: view, Generation: generation, URL: url, image: img}, nil
+}
+
+// spriteResult is a downloaded sprite. As text it is drawn in the terminal,
+// other formats describe where it came from.
+type spriteResult struct {
+	Pokemon    string `json:"pokemon"`
+	View       string `json:"view"`
+	Generation int    `json:"generation,omitempty"`
+	URL        string `json:"url"`
+	image      image.Image
+}
+
+func (s spriteResult) text(w io.Writer) error {
+	return termimage.Render(w, s.image, termimage.DetectColorMode())
+}
+
+func (s spriteResult) table() ([]string, [][]string) {
+	return []string{"pokemon", "view", "generation", "url"},
+		[][]string{{s.Pokemon, s.View, strconv.Itoa(s.Generation), s.URL}}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"pokedexcli/internal/api"
+	"testing"
+)
+
+func TestCommandSprite(t *testing.T) {
+	useCassette(t, "commands")
+	t.Setenv("COLORTERM", "truecolor")
+	commands := createRegistry()
+
+	var err error
+	out := captureOutput(commands, func() { err = commands.run("sprite magikarp") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The 4x4 fixture has a 2x2 opaque block, which renders as one line of two cells
+	expected := "\x1b[38;2;230;80;40m\x1b[48;2;250;220;120m▀\x1b[0m" +
+		"\x1b[38;2;230;80;40m\x1b[48;2;230;80;40m▀\x1b[0m\n"
+	if out != expected {
+		t.Errorf("unexpected sprite output: %q", out)
+	}
+}
+
+func TestSpriteOptions(t *testing.T) {
+	var sprites api.PokemonSprites
+	sprites.FrontDefault = "front.png"
+	sprites.FrontShiny = "shiny.png"
+	sprites.Versions.GenerationIii.RubySapphire.FrontDefault = "rs-front.png"
+	sprites.Versions.GenerationIii.FireredLeafgreen.BackDefault = "frlg-back.png"
+
+	cases := []struct {
+		view       string
+		generation int
+		expected   string
+	}{
+		{"front", 0, "front.png"},
+		{"shiny", 0, "shiny.png"},
+		{"back", 0, ""},
+		{"front", 3, "rs-front.png"},
+		{"back", 3, "frlg-back.png"},
+	}
+	for _, c := range cases {
+		if got := spriteURL(sprites, c.view, c.generation); got != c.expected {
+			t.Errorf("spriteURL(%s, %d) = %s, expected %s", c.view, c.generation, got, c.expected)
+		}
+	}
+
+	for _, s := range []string{"3", "iii", "gen3", "generation-iii"} {
+		if gen, ok := parseGeneration(s); !ok || gen != 3 {
+			t.Errorf("expected %s to parse as generation 3, got %d", s, gen)
+		}
+	}
+	if _, ok := parseGeneration("9"); ok {
+		t.Errorf("expected generation 9 to be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/lineedit"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run `go test -run TestTranscripts -update` to rewrite the golden transcripts from the current output
+var update = flag.Bool("update", false, "rewrite the golden transcripts in testdata/transcripts")
+
+const prompt = "Pokedex > "
+
+// TestTranscripts feeds the commands of every transcript in testdata/transcripts
+// through the REPL against the recorded PokeAPI and compares the whole session.
+// A transcript is what a user would see: each prompt followed by the command typed
+// after it, then the output of that command.
+func TestTranscripts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "transcripts", "*.txt"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no transcripts found: %v", err)
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			useCassette(t, "commands")
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error reading transcript: %v", err)
+			}
+			inputs := transcriptInputs(string(golden))
+
+			commands := createRegistry()
+			commands.config.rng = rand.New(rand.NewSource(1))
+			commands.config.now = func() time.Time { return time.Date(2026, 10, 18, 14, 30, 0, 0, time.UTC) }
+			commands.config.cryDir = t.TempDir()
+			out := &bytes.Buffer{}
+			commands.config.out = out
+
+			editor := lineedit.New(strings.NewReader(strings.Join(inputs, "\n")+"\n"), out)
+			if err := interactive(commands, editor, out); err != nil {
+				t.Fatalf("unexpected error shutting down: %v", err)
+			}
+
+			actual := buildTranscript(t, inputs, out.String())
+			if *update {
+				if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+					t.Fatalf("unexpected error writing transcript: %v", err)
+				}
+				return
+			}
+			if actual != string(golden) {
+				line, expected, got := firstDifference(string(golden), actual)
+				t.Errorf("transcript differs at line %d\nexpected: %q\n     got: %q", line, expected, got)
+			}
+		})
+	}
+}
+
+// transcriptInputs returns the commands typed after each prompt
+func transcriptInputs(transcript string) []string {
+	inputs := []string{}
+	for _, line := range strings.Split(transcript, "\n") {
+		if input, found := strings.CutPrefix(line, prompt); found {
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs
+}
+
+// buildTranscript puts each command back after the prompt it answered, since
+// the REPL does not echo input that is not typed at a terminal. A session that
+// ends at the end of input rather than with exit has one last prompt, which is
+// dropped so the shutdown output follows the last command.
+func buildTranscript(t *testing.T, inputs []string, output string) string {
+	t.Helper()
+	segments := strings.Split(output, prompt)
+	if len(segments) != len(inputs)+1 && len(segments) != len(inputs)+2 {
+		t.Fatalf("expected %d commands, got %d prompts in:\n%s", len(inputs), len(segments)-1, output)
+	}
+
+	var transcript strings.Builder
+	transcript.WriteString(segments[0])
+	for i, input := range inputs {
+		transcript.WriteString(prompt + input + "\n" + segments[i+1])
+	}
+	if len(segments) == len(inputs)+2 {
+		transcript.WriteString(segments[len(segments)-1])
+	}
+	return transcript.String()
+}
+
+func firstDifference(expected, actual string) (int, string, string) {
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for i := 0; i < max(len(expectedLines), len(actualLines)); i++ {
+		var e, a string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if e != a {
+			return i + 1, e, a
+		}
+	}
+	return 0, "", ""
+}